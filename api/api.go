@@ -2,6 +2,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/StevenWeathers/thunderdome-planning-poker/api/battle"
 	"github.com/StevenWeathers/thunderdome-planning-poker/api/retro"
 	"github.com/StevenWeathers/thunderdome-planning-poker/api/storyboard"
@@ -43,6 +45,14 @@ type Config struct {
 	FeatureStoryboard bool
 	// Whether Organizations (and Departments) feature is enabled
 	OrganizationsEnabled bool
+	// Whether importing plans from Jira XML is allowed
+	AllowJiraImport bool
+	// What happens when a battles last leader leaves, either promote or none
+	LeaderFailover string
+	// Seconds to wait for a leader to return before the leader failover
+	LeaderFailoverGraceSeconds int
+	// Whether assignees of overdue retro actions are emailed a reminder
+	RetroActionReminders bool
 }
 
 type api struct {
@@ -102,8 +112,15 @@ func Init(config *Config, router *mux.Router, database *db.Database, email *emai
 		cookie: cookie,
 		logger: logger,
 	}
-	b := battle.New(database, logger, a.validateSessionCookie, a.validateUserCookie)
-	rs := retro.New(database, logger, a.validateSessionCookie, a.validateUserCookie)
+	b := battle.New(
+		database, logger, email,
+		config.LeaderFailover, time.Duration(config.LeaderFailoverGraceSeconds)*time.Second,
+		a.validateSessionCookie, a.validateUserCookie,
+	)
+	rs := retro.New(
+		database, logger, email, config.RetroActionReminders,
+		a.validateSessionCookie, a.validateUserCookie,
+	)
 	sb := storyboard.New(database, logger, a.validateSessionCookie, a.validateUserCookie)
 	swaggerJsonPath := "/" + a.config.PathPrefix + "swagger/doc.json"
 
@@ -241,32 +258,154 @@ func Init(config *Config, router *mux.Router, database *db.Database, email *emai
 		teamRouter.HandleFunc("/{teamId}/battles", a.userOnly(a.teamUserOnly(a.handleGetTeamBattles()))).Methods("GET")
 		teamRouter.HandleFunc("/{teamId}/battles/{battleId}", a.userOnly(a.teamAdminOnly(a.handleTeamRemoveBattle()))).Methods("DELETE")
 		teamRouter.HandleFunc("/{teamId}/users/{userId}/battles", a.userOnly(a.teamUserOnly(a.handleBattleCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/plan-actuals", a.userOnly(a.departmentTeamAdminOnly(a.handleTeamPlanActuals()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/estimation-accuracy", a.userOnly(a.departmentTeamUserOnly(a.handleTeamEstimationAccuracy()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/plan-actuals", a.userOnly(a.orgTeamAdminOnly(a.handleTeamPlanActuals()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/estimation-accuracy", a.userOnly(a.orgTeamOnly(a.handleTeamEstimationAccuracy()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/plan-actuals", a.userOnly(a.teamAdminOnly(a.handleTeamPlanActuals()))).Methods("PUT")
+		teamRouter.HandleFunc("/{teamId}/estimation-accuracy", a.userOnly(a.teamUserOnly(a.handleTeamEstimationAccuracy()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/velocity", a.userOnly(a.departmentTeamUserOnly(a.handleTeamVelocity()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/velocity", a.userOnly(a.orgTeamOnly(a.handleTeamVelocity()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/velocity", a.userOnly(a.teamUserOnly(a.handleTeamVelocity()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints", a.userOnly(a.departmentTeamUserOnly(a.handleSprintsGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints", a.userOnly(a.departmentTeamUserOnly(a.handleSprintCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}", a.userOnly(a.departmentTeamUserOnly(a.handleSprintGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}", a.userOnly(a.departmentTeamUserOnly(a.handleSprintUpdate()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}", a.userOnly(a.departmentTeamAdminOnly(a.handleSprintDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/burnup", a.userOnly(a.departmentTeamUserOnly(a.handleSprintBurnup()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}", a.userOnly(a.departmentTeamUserOnly(a.handleSprintPlanCommit()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}", a.userOnly(a.departmentTeamUserOnly(a.handleSprintPlanUncommit()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}/done", a.userOnly(a.departmentTeamUserOnly(a.handleSprintPlanDone()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints", a.userOnly(a.orgTeamOnly(a.handleSprintsGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints", a.userOnly(a.orgTeamOnly(a.handleSprintCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}", a.userOnly(a.orgTeamOnly(a.handleSprintGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}", a.userOnly(a.orgTeamOnly(a.handleSprintUpdate()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}", a.userOnly(a.orgTeamAdminOnly(a.handleSprintDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}/burnup", a.userOnly(a.orgTeamOnly(a.handleSprintBurnup()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}", a.userOnly(a.orgTeamOnly(a.handleSprintPlanCommit()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}", a.userOnly(a.orgTeamOnly(a.handleSprintPlanUncommit()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}/done", a.userOnly(a.orgTeamOnly(a.handleSprintPlanDone()))).Methods("PUT")
+		teamRouter.HandleFunc("/{teamId}/sprints", a.userOnly(a.teamUserOnly(a.handleSprintsGet()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/sprints", a.userOnly(a.teamUserOnly(a.handleSprintCreate()))).Methods("POST")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}", a.userOnly(a.teamUserOnly(a.handleSprintGet()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}", a.userOnly(a.teamUserOnly(a.handleSprintUpdate()))).Methods("PUT")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}", a.userOnly(a.teamAdminOnly(a.handleSprintDelete()))).Methods("DELETE")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}/burnup", a.userOnly(a.teamUserOnly(a.handleSprintBurnup()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}/plans/{planId}", a.userOnly(a.teamUserOnly(a.handleSprintPlanCommit()))).Methods("PUT")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}/plans/{planId}", a.userOnly(a.teamUserOnly(a.handleSprintPlanUncommit()))).Methods("DELETE")
+		teamRouter.HandleFunc("/{teamId}/sprints/{sprintId}/plans/{planId}/done", a.userOnly(a.teamUserOnly(a.handleSprintPlanDone()))).Methods("PUT")
+		userRouter.HandleFunc("/{userId}/estimation-scales", a.userOnly(a.entityUserOnly(a.handleEstimationScalesGet()))).Methods("GET")
+		userRouter.HandleFunc("/{userId}/estimation-scales", a.userOnly(a.entityUserOnly(a.handleEstimationScaleCreate()))).Methods("POST")
+		userRouter.HandleFunc("/{userId}/estimation-scales/{scaleId}", a.userOnly(a.entityUserOnly(a.handleEstimationScaleUpdate()))).Methods("PUT")
+		userRouter.HandleFunc("/{userId}/estimation-scales/{scaleId}", a.userOnly(a.entityUserOnly(a.handleEstimationScaleDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales", a.userOnly(a.departmentTeamUserOnly(a.handleEstimationScalesGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales", a.userOnly(a.departmentTeamAdminOnly(a.handleEstimationScaleCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales/{scaleId}", a.userOnly(a.departmentTeamAdminOnly(a.handleEstimationScaleUpdate()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales/{scaleId}", a.userOnly(a.departmentTeamAdminOnly(a.handleEstimationScaleDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/estimation-scales", a.userOnly(a.orgTeamOnly(a.handleEstimationScalesGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/estimation-scales", a.userOnly(a.orgTeamAdminOnly(a.handleEstimationScaleCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/estimation-scales/{scaleId}", a.userOnly(a.orgTeamAdminOnly(a.handleEstimationScaleUpdate()))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/estimation-scales/{scaleId}", a.userOnly(a.orgTeamAdminOnly(a.handleEstimationScaleDelete()))).Methods("DELETE")
+		teamRouter.HandleFunc("/{teamId}/estimation-scales", a.userOnly(a.teamUserOnly(a.handleEstimationScalesGet()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/estimation-scales", a.userOnly(a.teamAdminOnly(a.handleEstimationScaleCreate()))).Methods("POST")
+		teamRouter.HandleFunc("/{teamId}/estimation-scales/{scaleId}", a.userOnly(a.teamAdminOnly(a.handleEstimationScaleUpdate()))).Methods("PUT")
+		teamRouter.HandleFunc("/{teamId}/estimation-scales/{scaleId}", a.userOnly(a.teamAdminOnly(a.handleEstimationScaleDelete()))).Methods("DELETE")
+		userRouter.HandleFunc("/{userId}/battle-templates", a.userOnly(a.entityUserOnly(a.handleBattleTemplatesGet()))).Methods("GET")
+		userRouter.HandleFunc("/{userId}/battle-templates", a.userOnly(a.entityUserOnly(a.handleBattleTemplateCreate()))).Methods("POST")
+		userRouter.HandleFunc("/{userId}/battle-templates/{templateId}", a.userOnly(a.entityUserOnly(a.handleBattleTemplateDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/battle-templates", a.userOnly(a.departmentTeamUserOnly(a.handleBattleTemplatesGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/battle-templates", a.userOnly(a.departmentTeamAdminOnly(a.handleBattleTemplateCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/battle-templates/{templateId}", a.userOnly(a.departmentTeamAdminOnly(a.handleBattleTemplateDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/battle-templates", a.userOnly(a.orgTeamOnly(a.handleBattleTemplatesGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/battle-templates", a.userOnly(a.orgTeamAdminOnly(a.handleBattleTemplateCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/battle-templates/{templateId}", a.userOnly(a.orgTeamAdminOnly(a.handleBattleTemplateDelete()))).Methods("DELETE")
+		teamRouter.HandleFunc("/{teamId}/battle-templates", a.userOnly(a.teamUserOnly(a.handleBattleTemplatesGet()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/battle-templates", a.userOnly(a.teamAdminOnly(a.handleBattleTemplateCreate()))).Methods("POST")
+		teamRouter.HandleFunc("/{teamId}/battle-templates/{templateId}", a.userOnly(a.teamAdminOnly(a.handleBattleTemplateDelete()))).Methods("DELETE")
 		apiRouter.HandleFunc("/maintenance/clean-battles", a.userOnly(a.adminOnly(a.handleCleanBattles()))).Methods("DELETE")
 		apiRouter.HandleFunc("/battles", a.userOnly(a.adminOnly(a.handleGetBattles()))).Methods("GET")
 		apiRouter.HandleFunc("/battles/{battleId}", a.userOnly(a.handleGetBattle())).Methods("GET")
+		apiRouter.HandleFunc("/battles/{battleId}", a.userOnly(a.handleBattleRevise(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}", a.userOnly(a.handleBattleDelete(b))).Methods("DELETE")
+		apiRouter.HandleFunc("/battles/{battleId}/export", a.userOnly(a.handleBattleExport())).Methods("GET")
+		apiRouter.HandleFunc("/battles/{battleId}/clone", a.userOnly(a.handleBattleClone())).Methods("POST")
 		apiRouter.HandleFunc("/battles/{battleId}/plans", a.userOnly(a.handleBattlePlanAdd(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/import", a.userOnly(a.handleBattlePlansImport(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plan-order", a.userOnly(a.handleBattlePlansReorder(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}", a.userOnly(a.handleBattlePlanRevise(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}", a.userOnly(a.handleBattlePlanDelete(b))).Methods("DELETE")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/activate", a.userOnly(a.handleBattlePlanActivate(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/revote", a.userOnly(a.handleBattlePlanRevote(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/skip", a.userOnly(a.handleBattlePlanSkip(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/end-voting", a.userOnly(a.handleBattlePlanVoteEnd(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/finalize", a.userOnly(a.handleBattlePlanFinalize(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/vote", a.userOnly(a.handleBattlePlanVote(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/vote", a.userOnly(a.handleBattlePlanVoteRetract(b))).Methods("DELETE")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/actual", a.userOnly(a.handleBattlePlanActual(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/comments", a.userOnly(a.handleBattlePlanCommentAdd(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/comments/{commentId}", a.userOnly(a.handleBattlePlanCommentEdit(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}/plans/{planId}/comments/{commentId}", a.userOnly(a.handleBattlePlanCommentDelete(b))).Methods("DELETE")
+		apiRouter.HandleFunc("/battles/{battleId}/async-voting", a.userOnly(a.handleBattleAsyncVotingStart(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/leaders/{userId}", a.userOnly(a.handleBattleLeaderAdd(b))).Methods("PUT")
+		apiRouter.HandleFunc("/battles/{battleId}/leaders/{userId}", a.userOnly(a.handleBattleLeaderRemove(b))).Methods("DELETE")
+		apiRouter.HandleFunc("/battles/{battleId}/users/{userId}/nudge", a.userOnly(a.handleBattleUserNudge(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/breakouts", a.userOnly(a.handleBattleBreakoutsStart(b))).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/breakouts", a.userOnly(a.handleBattleBreakoutsEnd(b))).Methods("DELETE")
+		apiRouter.HandleFunc("/battles/{battleId}/observer-tokens", a.userOnly(a.handleObserverTokensGet())).Methods("GET")
+		apiRouter.HandleFunc("/battles/{battleId}/observer-tokens", a.userOnly(a.handleObserverTokenCreate())).Methods("POST")
+		apiRouter.HandleFunc("/battles/{battleId}/observer-tokens/{tokenId}", a.userOnly(a.handleObserverTokenDelete())).Methods("DELETE")
 		apiRouter.HandleFunc("/arena/{battleId}", b.ServeBattleWs())
 	}
 	// retro(s)
 	if a.config.FeatureRetro {
 		userRouter.HandleFunc("/{userId}/retros", a.userOnly(a.entityUserOnly(a.handleRetroCreate()))).Methods("POST")
 		userRouter.HandleFunc("/{userId}/retros", a.userOnly(a.entityUserOnly(a.handleRetrosGetByUser()))).Methods("GET")
+		userRouter.HandleFunc("/{userId}/retro-templates", a.userOnly(a.entityUserOnly(a.handleRetroTemplatesGet()))).Methods("GET")
+		userRouter.HandleFunc("/{userId}/retro-templates", a.userOnly(a.entityUserOnly(a.handleRetroTemplateCreate()))).Methods("POST")
+		userRouter.HandleFunc("/{userId}/retro-templates/{templateId}", a.userOnly(a.entityUserOnly(a.handleRetroTemplateDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-templates", a.userOnly(a.departmentTeamUserOnly(a.handleRetroTemplatesGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-templates", a.userOnly(a.departmentTeamAdminOnly(a.handleRetroTemplateCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-templates/{templateId}", a.userOnly(a.departmentTeamAdminOnly(a.handleRetroTemplateDelete()))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-templates", a.userOnly(a.orgTeamOnly(a.handleRetroTemplatesGet()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-templates", a.userOnly(a.orgTeamAdminOnly(a.handleRetroTemplateCreate()))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-templates/{templateId}", a.userOnly(a.orgTeamAdminOnly(a.handleRetroTemplateDelete()))).Methods("DELETE")
+		teamRouter.HandleFunc("/{teamId}/retro-templates", a.userOnly(a.teamUserOnly(a.handleRetroTemplatesGet()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/retro-templates", a.userOnly(a.teamAdminOnly(a.handleRetroTemplateCreate()))).Methods("POST")
+		teamRouter.HandleFunc("/{teamId}/retro-templates/{templateId}", a.userOnly(a.teamAdminOnly(a.handleRetroTemplateDelete()))).Methods("DELETE")
 		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retros", a.userOnly(a.departmentTeamUserOnly(a.handleGetTeamRetros()))).Methods("GET")
 		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retros/{retroId}", a.userOnly(a.departmentTeamAdminOnly(a.handleTeamRemoveRetro()))).Methods("DELETE")
 		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions", a.userOnly(a.departmentTeamUserOnly(a.handleGetTeamRetroActions()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/comments", a.userOnly(a.departmentTeamUserOnly(a.handleRetroActionCommentAdd(rs)))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId}", a.userOnly(a.departmentTeamUserOnly(a.handleRetroActionCommentEdit(rs)))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId}", a.userOnly(a.departmentTeamUserOnly(a.handleRetroActionCommentDelete(rs)))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/assignees", a.userOnly(a.departmentTeamUserOnly(a.handleRetroActionAssigneeAdd(rs)))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/assignees/{userId}", a.userOnly(a.departmentTeamUserOnly(a.handleRetroActionAssigneeDelete(rs)))).Methods("DELETE")
 		orgRouter.HandleFunc("/{orgId}/departments/{departmentId}/teams/{teamId}/users/{userId}/retros", a.userOnly(a.departmentTeamUserOnly(a.handleRetroCreate()))).Methods("POST")
 		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retros", a.userOnly(a.orgTeamOnly(a.handleGetTeamRetros()))).Methods("GET")
 		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-actions", a.userOnly(a.orgTeamOnly(a.handleGetTeamRetroActions()))).Methods("GET")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-actions/{actionId}/comments", a.userOnly(a.orgTeamOnly(a.handleRetroActionCommentAdd(rs)))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId}", a.userOnly(a.orgTeamOnly(a.handleRetroActionCommentEdit(rs)))).Methods("PUT")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId}", a.userOnly(a.orgTeamOnly(a.handleRetroActionCommentDelete(rs)))).Methods("DELETE")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-actions/{actionId}/assignees", a.userOnly(a.orgTeamOnly(a.handleRetroActionAssigneeAdd(rs)))).Methods("POST")
+		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retro-actions/{actionId}/assignees/{userId}", a.userOnly(a.orgTeamOnly(a.handleRetroActionAssigneeDelete(rs)))).Methods("DELETE")
 		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/retros/{retroId}", a.userOnly(a.orgTeamAdminOnly(a.handleTeamRemoveRetro()))).Methods("DELETE")
 		orgRouter.HandleFunc("/{orgId}/teams/{teamId}/users/{userId}/retros", a.userOnly(a.orgTeamOnly(a.handleRetroCreate()))).Methods("POST")
 		teamRouter.HandleFunc("/{teamId}/retros", a.userOnly(a.teamUserOnly(a.handleGetTeamRetros()))).Methods("GET")
 		teamRouter.HandleFunc("/{teamId}/retros/{retroId}", a.userOnly(a.teamAdminOnly(a.handleTeamRemoveRetro()))).Methods("DELETE")
 		teamRouter.HandleFunc("/{teamId}/retro-actions", a.userOnly(a.teamUserOnly(a.handleGetTeamRetroActions()))).Methods("GET")
+		teamRouter.HandleFunc("/{teamId}/retro-actions/{actionId}/comments", a.userOnly(a.teamUserOnly(a.handleRetroActionCommentAdd(rs)))).Methods("POST")
+		teamRouter.HandleFunc("/{teamId}/retro-actions/{actionId}/comments/{commentId}", a.userOnly(a.teamUserOnly(a.handleRetroActionCommentEdit(rs)))).Methods("PUT")
+		teamRouter.HandleFunc("/{teamId}/retro-actions/{actionId}/comments/{commentId}", a.userOnly(a.teamUserOnly(a.handleRetroActionCommentDelete(rs)))).Methods("DELETE")
+		teamRouter.HandleFunc("/{teamId}/retro-actions/{actionId}/assignees", a.userOnly(a.teamUserOnly(a.handleRetroActionAssigneeAdd(rs)))).Methods("POST")
+		teamRouter.HandleFunc("/{teamId}/retro-actions/{actionId}/assignees/{userId}", a.userOnly(a.teamUserOnly(a.handleRetroActionAssigneeDelete(rs)))).Methods("DELETE")
 		teamRouter.HandleFunc("/{teamId}/users/{userId}/retros", a.userOnly(a.teamUserOnly(a.handleRetroCreate()))).Methods("POST")
 		apiRouter.HandleFunc("/maintenance/clean-retros", a.userOnly(a.adminOnly(a.handleCleanRetros()))).Methods("DELETE")
 		apiRouter.HandleFunc("/retros", a.userOnly(a.adminOnly(a.handleGetRetros()))).Methods("GET")
 		apiRouter.HandleFunc("/retros/{retroId}", a.userOnly(a.handleRetroGet())).Methods("GET")
 		apiRouter.HandleFunc("/retros/{retroId}/actions/{actionId}", a.userOnly(a.handleRetroActionUpdate(rs))).Methods("PUT")
+		apiRouter.HandleFunc("/retros/{retroId}/observer-tokens", a.userOnly(a.handleObserverTokensGet())).Methods("GET")
+		apiRouter.HandleFunc("/retros/{retroId}/observer-tokens", a.userOnly(a.handleObserverTokenCreate())).Methods("POST")
+		apiRouter.HandleFunc("/retros/{retroId}/observer-tokens/{tokenId}", a.userOnly(a.handleObserverTokenDelete())).Methods("DELETE")
 		apiRouter.HandleFunc("/retro/{retroId}", rs.ServeWs())
 	}
 	// storyboard(s)
@@ -285,6 +424,9 @@ func Init(config *Config, router *mux.Router, database *db.Database, email *emai
 		apiRouter.HandleFunc("/maintenance/clean-storyboards", a.userOnly(a.adminOnly(a.handleCleanStoryboards()))).Methods("DELETE")
 		apiRouter.HandleFunc("/storyboards", a.userOnly(a.adminOnly(a.handleGetStoryboards()))).Methods("GET")
 		apiRouter.HandleFunc("/storyboards/{storyboardId}", a.userOnly(a.handleStoryboardGet())).Methods("GET")
+		apiRouter.HandleFunc("/storyboards/{storyboardId}/observer-tokens", a.userOnly(a.handleObserverTokensGet())).Methods("GET")
+		apiRouter.HandleFunc("/storyboards/{storyboardId}/observer-tokens", a.userOnly(a.handleObserverTokenCreate())).Methods("POST")
+		apiRouter.HandleFunc("/storyboards/{storyboardId}/observer-tokens/{tokenId}", a.userOnly(a.handleObserverTokenDelete())).Methods("DELETE")
 		apiRouter.HandleFunc("/storyboard/{storyboardId}", sb.ServeWs())
 	}
 
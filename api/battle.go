@@ -47,12 +47,19 @@ func (a *api) handleGetUserBattles() http.HandlerFunc {
 }
 
 type battleRequestBody struct {
-	BattleName           string        `json:"name"`
-	PointValuesAllowed   []string      `json:"pointValuesAllowed"`
-	AutoFinishVoting     bool          `json:"autoFinishVoting"`
-	Plans                []*model.Plan `json:"plans"`
-	PointAverageRounding string        `json:"pointAverageRounding"`
-	BattleLeaders        []string      `json:"battleLeaders"`
+	BattleName           string                   `json:"name"`
+	PointValuesAllowed   []string                 `json:"pointValuesAllowed"`
+	AutoFinishVoting     bool                     `json:"autoFinishVoting"`
+	Plans                []*model.Plan            `json:"plans"`
+	PointAverageRounding string                   `json:"pointAverageRounding"`
+	VotingTimerSeconds   int                      `json:"votingTimerSeconds"`
+	EstimationScaleID    string                   `json:"estimationScaleId"`
+	AnonymousVoting      bool                     `json:"anonymousVoting"`
+	VotingMode           string                   `json:"votingMode" enums:"live,async"`
+	Dimensions           []*model.BattleDimension `json:"dimensions"`
+	DimensionFormula     string                   `json:"dimensionFormula"`
+	BattleLeaders        []string                 `json:"battleLeaders"`
+	TemplateID           string                   `json:"templateId"`
 }
 
 // handleBattleCreate handles creating a battle (arena)
@@ -92,12 +99,92 @@ func (a *api) handleBattleCreate() http.HandlerFunc {
 			return
 		}
 
-		newBattle, err := a.db.CreateBattle(UserID, b.BattleName, b.PointValuesAllowed, b.Plans, b.AutoFinishVoting, b.PointAverageRounding)
+		if b.VotingTimerSeconds < 0 {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_VOTING_TIMER"))
+			return
+		}
+
+		if b.VotingMode == "" {
+			b.VotingMode = "live"
+		} else if b.VotingMode != "live" && b.VotingMode != "async" {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_VOTING_MODE"))
+			return
+		}
+
+		// when created from a template its settings are used, only the name and plans come from the request
+		var TemplateLeaders []string
+		if b.TemplateID != "" {
+			if err := a.db.ConfirmBattleTemplateAccess(b.TemplateID, UserID); err != nil {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
+			Template, err := a.db.BattleTemplateGet(b.TemplateID)
+			if err != nil {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
+			if b.BattleName == "" {
+				b.BattleName = Template.BattleName
+			}
+			b.PointValuesAllowed = Template.PointValuesAllowed
+			b.EstimationScaleID = Template.EstimationScaleID
+			b.AutoFinishVoting = Template.AutoFinishVoting
+			b.PointAverageRounding = Template.PointAverageRounding
+			b.VotingTimerSeconds = Template.VotingTimerSeconds
+			b.AnonymousVoting = Template.AnonymousVoting
+			b.VotingMode = Template.VotingMode
+			b.Dimensions = Template.Dimensions
+			b.DimensionFormula = Template.DimensionFormula
+			TemplateLeaders = Template.Leaders
+		} else if b.EstimationScaleID != "" {
+			if err := a.db.ConfirmEstimationScaleAccess(b.EstimationScaleID, UserID); err != nil {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
+		} else if len(b.PointValuesAllowed) == 0 {
+			b.EstimationScaleID = a.defaultEstimationScaleID(UserID, vars["teamId"])
+		}
+
+		for _, dim := range b.Dimensions {
+			if dim.EstimationScaleID != "" {
+				if err := a.db.ConfirmEstimationScaleAccess(dim.EstimationScaleID, UserID); err != nil {
+					a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+					return
+				}
+			}
+		}
+		if err := a.db.PrepareBattleDimensions(b.Dimensions, b.DimensionFormula); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		newBattle, err := a.db.CreateBattle(UserID, b.BattleName, b.PointValuesAllowed, b.Plans, b.AutoFinishVoting, b.PointAverageRounding, b.VotingTimerSeconds, b.EstimationScaleID, b.AnonymousVoting, b.VotingMode)
 		if err != nil {
 			a.Failure(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
+		if len(b.Dimensions) > 0 {
+			newBattle.Dimensions, err = a.db.SetBattleDimensions(newBattle.Id, b.Dimensions, b.DimensionFormula)
+			if err != nil {
+				a.Failure(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			newBattle.DimensionFormula = b.DimensionFormula
+		}
+
+		for _, LeaderID := range TemplateLeaders {
+			if LeaderID == UserID {
+				continue
+			}
+			updatedLeaders, err := a.db.SetBattleLeader(newBattle.Id, LeaderID)
+			if err != nil {
+				a.logger.Error("error adding template battle leader")
+			} else {
+				newBattle.Leaders = updatedLeaders
+			}
+		}
+
 		// when battleLeaders array is passed add additional leaders to battle
 		if len(b.BattleLeaders) > 0 {
 			updatedLeaders, err := a.db.AddBattleLeadersByEmail(newBattle.Id, b.BattleLeaders)
@@ -240,20 +327,224 @@ func (a *api) handleBattlePlanAdd(b *battle.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		BattleID := vars["battleId"]
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, BattleID, "add_plan", string(body))
+	}
+}
+
+type planVoteRequestBody struct {
+	VoteValue  string `json:"voteValue"`
+	Dimension  string `json:"dimension"`
+	Confidence *int   `json:"confidence" minimum:"1" maximum:"5"`
+}
+
+// handleBattlePlanVote handles a battle user voting on an active plan
+// @Summary Vote on Battle Plan
+// @Description Sets the users vote on an active battle plan, used in async voting battles when users are not connected
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param vote body planVoteRequestBody true "vote object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Success 403 object standardJsonResponse{}
+// @Success 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/vote [put]
+func (a *api) handleBattlePlanVote(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		BattleID := vars["battleId"]
+		PlanID := vars["planId"]
 		UserID := r.Context().Value(contextKeyUserID).(string)
 
+		if err := a.db.ConfirmBattleUser(BattleID, UserID); err != nil {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, err.Error()))
+			return
+		}
+
 		body, bodyErr := ioutil.ReadAll(r.Body)
 		if bodyErr != nil {
 			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
 			return
 		}
 
-		err := b.APIEvent(BattleID, UserID, "add_plan", string(body))
+		var v = planVoteRequestBody{}
+		if jsonErr := json.Unmarshal(body, &v); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		Battle, err := a.db.GetBattle(BattleID, UserID)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, "BATTLE_NOT_FOUND"))
+			return
+		}
+
+		vote, _ := json.Marshal(map[string]interface{}{
+			"planId":           PlanID,
+			"voteValue":        v.VoteValue,
+			"autoFinishVoting": Battle.AutoFinishVoting,
+			"dimension":        v.Dimension,
+			"confidence":       v.Confidence,
+		})
+		a.battleEvent(w, r, b, BattleID, "vote", string(vote))
+	}
+}
+
+type planOrderRequestBody struct {
+	PlanIDs []string `json:"planIds"`
+}
+
+// handleBattlePlansReorder handles setting the order of the battles plans
+// @Summary Reorder Battle Plans
+// @Description Sets the order of the battles plans, plans not included keep their relative order after the listed plans
+// @Param battleId path string true "the battle ID"
+// @Param order body planOrderRequestBody true "ordered list of plan IDs"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Success 403 object standardJsonResponse{}
+// @Success 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plan-order [put]
+func (a *api) handleBattlePlansReorder(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		BattleID := vars["battleId"]
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, BattleID, "reorder_plans", string(body))
+	}
+}
+
+type battleCloneRequestBody struct {
+	BattleName   string `json:"name"`
+	IncludePlans bool   `json:"includePlans"`
+	TeamID       string `json:"teamId"`
+}
+
+// handleBattleClone handles copying a battles settings, leaders and optionally unfinished plans into a new battle
+// @Summary Clone Battle
+// @Description Copies a battles settings, leaders and optionally its unfinished plans into a new battle
+// @Tags battle
+// @Produce  json
+// @Param battleId path string true "the battle ID to clone"
+// @Param clone body battleCloneRequestBody false "clone options"
+// @Success 200 object standardJsonResponse{data=model.Battle}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/clone [post]
+func (a *api) handleBattleClone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		BattleID := vars["battleId"]
+		UserID := r.Context().Value(contextKeyUserID).(string)
+		UserType := r.Context().Value(contextKeyUserType).(string)
+
+		var c = battleCloneRequestBody{}
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+		if len(body) > 0 {
+			if jsonErr := json.Unmarshal(body, &c); jsonErr != nil {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+				return
+			}
+		}
+
+		if err := a.db.ConfirmLeader(BattleID, UserID); err != nil && UserType != adminUserType {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, "REQUIRES_BATTLE_LEADER"))
+			return
+		}
+
+		if c.TeamID != "" {
+			if _, err := a.db.TeamUserRole(UserID, c.TeamID); err != nil && UserType != adminUserType {
+				a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, "REQUIRES_TEAM_USER"))
+				return
+			}
+		}
+
+		Battle, err := a.db.GetBattle(BattleID, UserID)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, "BATTLE_NOT_FOUND"))
+			return
+		}
+
+		if c.BattleName == "" {
+			c.BattleName = Battle.Name
+		}
+
+		Plans := make([]*model.Plan, 0)
+		if c.IncludePlans {
+			for _, p := range Battle.Plans {
+				if p.Points != "" || p.Skipped {
+					continue
+				}
+				Plans = append(Plans, &model.Plan{
+					Name:               p.Name,
+					Type:               p.Type,
+					ReferenceId:        p.ReferenceId,
+					Link:               p.Link,
+					Description:        p.Description,
+					AcceptanceCriteria: p.AcceptanceCriteria,
+				})
+			}
+		}
+
+		newBattle, err := a.db.CreateBattle(
+			UserID, c.BattleName, Battle.PointValuesAllowed, Plans,
+			Battle.AutoFinishVoting, Battle.PointAverageRounding, Battle.VotingTimerSeconds,
+			Battle.EstimationScaleID, Battle.AnonymousVoting, Battle.VotingMode,
+		)
 		if err != nil {
 			a.Failure(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		a.Success(w, r, http.StatusOK, nil, nil)
+		if len(Battle.Dimensions) > 0 {
+			newBattle.Dimensions, err = a.db.SetBattleDimensions(newBattle.Id, Battle.Dimensions, Battle.DimensionFormula)
+			if err != nil {
+				a.Failure(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			newBattle.DimensionFormula = Battle.DimensionFormula
+		}
+
+		for _, LeaderID := range Battle.Leaders {
+			if LeaderID == UserID {
+				continue
+			}
+			updatedLeaders, err := a.db.SetBattleLeader(newBattle.Id, LeaderID)
+			if err != nil {
+				a.logger.Error("error adding cloned battle leader")
+			} else {
+				newBattle.Leaders = updatedLeaders
+			}
+		}
+
+		if c.TeamID != "" {
+			if err := a.db.TeamAddBattle(c.TeamID, newBattle.Id); err != nil {
+				a.Failure(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		a.Success(w, r, http.StatusOK, newBattle, nil)
 	}
 }
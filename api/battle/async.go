@@ -0,0 +1,124 @@
+package battle
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// asyncDeadlineInterval how often async voting battles are checked for a passed deadline
+const asyncDeadlineInterval = 30 * time.Second
+
+// StartAsyncVoting handles opening voting on multiple plans at once until the deadline in an async voting battle
+func (b *Service) StartAsyncVoting(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var av struct {
+		PlanIDs  []string  `json:"planIds"`
+		Deadline time.Time `json:"deadline"`
+	}
+	if err := json.Unmarshal([]byte(EventValue), &av); err != nil {
+		return nil, err, false
+	}
+
+	VotingMode, err := b.db.GetBattleVotingMode(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+	if VotingMode != "async" {
+		return nil, errors.New("BATTLE_NOT_ASYNC"), false
+	}
+	if len(av.PlanIDs) == 0 || !av.Deadline.After(time.Now()) {
+		return nil, errors.New("INVALID_ASYNC_VOTING"), false
+	}
+
+	b.stopVotingTimer(BattleID)
+	plans, err := b.db.StartAsyncPlanVoting(BattleID, av.PlanIDs, av.Deadline)
+	if err != nil {
+		return nil, err, false
+	}
+	updatedPlans, _ := json.Marshal(plans)
+	msg := createSocketEvent("async_voting_started", string(updatedPlans), "")
+
+	return msg, nil, false
+}
+
+// watchAsyncDeadlines periodically closes async voting for battles whose deadline has passed
+func (b *Service) watchAsyncDeadlines() {
+	ticker := time.NewTicker(asyncDeadlineInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		BattleIDs, err := b.db.GetExpiredAsyncBattles()
+		if err != nil {
+			continue
+		}
+
+		for _, BattleID := range BattleIDs {
+			b.closeAsyncVoting(BattleID)
+		}
+	}
+}
+
+// closeAsyncVoting ends voting on all the battles still active plans
+func (b *Service) closeAsyncVoting(BattleID string) {
+	var plans []*model.Plan
+
+	for _, p := range b.db.GetPlans(BattleID, "") {
+		if !p.Active {
+			continue
+		}
+
+		var err error
+		plans, err = b.db.EndPlanVoting(BattleID, p.Id)
+		if err != nil {
+			b.logger.Error("async voting deadline end voting error", zap.Error(err))
+			return
+		}
+	}
+
+	if plans == nil {
+		return
+	}
+
+	updatedPlans, _ := json.Marshal(plans)
+	b.BroadcastEvent(BattleID, "voting_ended", string(updatedPlans))
+	b.sendAsyncVotingSummary(BattleID, plans)
+}
+
+// asyncVotingClosed checks whether the last of the async voting plans has been closed
+func asyncVotingClosed(Plans []*model.Plan) bool {
+	for _, p := range Plans {
+		if p.Active {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sendAsyncVotingSummary notifies the battle leaders of the voted plans awaiting their final points
+func (b *Service) sendAsyncVotingSummary(BattleID string, Plans []*model.Plan) {
+	var Voted = make([]*model.Plan, 0)
+	for _, p := range Plans {
+		if p.Results != nil && p.Points == "" && !p.Skipped {
+			Voted = append(Voted, p)
+		}
+	}
+
+	summary, _ := json.Marshal(Voted)
+	b.BroadcastEvent(BattleID, "async_voting_summary", string(summary))
+
+	if b.email == nil {
+		return
+	}
+
+	Battle, err := b.db.GetBattle(BattleID, "")
+	if err != nil {
+		return
+	}
+	for _, leader := range b.db.GetBattleLeaderUsers(BattleID) {
+		_ = b.email.SendAsyncVotingSummary(leader.Name, leader.Email, Battle.Name, BattleID, Voted)
+	}
+}
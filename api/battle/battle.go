@@ -3,55 +3,82 @@ package battle
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/StevenWeathers/thunderdome-planning-poker/db"
+	"github.com/StevenWeathers/thunderdome-planning-poker/email"
 	"go.uber.org/zap"
 )
 
 // Service provides battle service
 type Service struct {
-	db                    *db.Database
-	logger                *zap.Logger
-	validateSessionCookie func(w http.ResponseWriter, r *http.Request) (string, error)
-	validateUserCookie    func(w http.ResponseWriter, r *http.Request) (string, error)
-	eventHandlers         map[string]func(string, string, string) ([]byte, error, bool)
+	db                        *db.Database
+	logger                    *zap.Logger
+	email                     *email.Email
+	validateSessionCookie     func(w http.ResponseWriter, r *http.Request) (string, error)
+	validateUserCookie        func(w http.ResponseWriter, r *http.Request) (string, error)
+	eventHandlers             map[string]func(string, string, string) ([]byte, error, bool)
+	timers                    map[string]*votingTimer
+	timersMu                  sync.Mutex
+	leaderFailoverPolicy      string
+	leaderFailoverGracePeriod time.Duration
+	failovers                 map[string]*time.Timer
+	failoversMu               sync.Mutex
 }
 
 // New returns a new battle with websocket hub/client and event handlers
 func New(
 	db *db.Database,
 	logger *zap.Logger,
+	email *email.Email,
+	LeaderFailoverPolicy string,
+	LeaderFailoverGracePeriod time.Duration,
 	validateSessionCookie func(w http.ResponseWriter, r *http.Request) (string, error),
 	validateUserCookie func(w http.ResponseWriter, r *http.Request) (string, error),
 ) *Service {
 	b := &Service{
-		db:                    db,
-		logger:                logger,
-		validateSessionCookie: validateSessionCookie,
-		validateUserCookie:    validateUserCookie,
+		db:                        db,
+		logger:                    logger,
+		email:                     email,
+		validateSessionCookie:     validateSessionCookie,
+		validateUserCookie:        validateUserCookie,
+		timers:                    make(map[string]*votingTimer),
+		leaderFailoverPolicy:      LeaderFailoverPolicy,
+		leaderFailoverGracePeriod: LeaderFailoverGracePeriod,
+		failovers:                 make(map[string]*time.Timer),
 	}
 
 	b.eventHandlers = map[string]func(string, string, string) ([]byte, error, bool){
-		"jab_warrior":      b.UserNudge,
-		"vote":             b.UserVote,
-		"retract_vote":     b.UserVoteRetract,
-		"end_voting":       b.PlanVoteEnd,
-		"add_plan":         b.PlanAdd,
-		"revise_plan":      b.PlanRevise,
-		"burn_plan":        b.PlanDelete,
-		"activate_plan":    b.PlanActivate,
-		"skip_plan":        b.PlanSkip,
-		"finalize_plan":    b.PlanFinalize,
-		"promote_leader":   b.UserPromote,
-		"demote_leader":    b.UserDemote,
-		"become_leader":    b.UserPromoteSelf,
-		"spectator_toggle": b.UserSpectatorToggle,
-		"revise_battle":    b.Revise,
-		"concede_battle":   b.Delete,
-		"abandon_battle":   b.Abandon,
+		"jab_warrior":         b.UserNudge,
+		"vote":                b.UserVote,
+		"retract_vote":        b.UserVoteRetract,
+		"end_voting":          b.PlanVoteEnd,
+		"add_plan":            b.PlanAdd,
+		"revise_plan":         b.PlanRevise,
+		"burn_plan":           b.PlanDelete,
+		"reorder_plans":       b.PlansReorder,
+		"activate_plan":       b.PlanActivate,
+		"revote_plan":         b.PlanRevote,
+		"start_async_voting":  b.StartAsyncVoting,
+		"start_breakouts":     b.StartBreakouts,
+		"end_breakouts":       b.EndBreakouts,
+		"skip_plan":           b.PlanSkip,
+		"finalize_plan":       b.PlanFinalize,
+		"add_plan_comment":    b.PlanCommentAdd,
+		"edit_plan_comment":   b.PlanCommentEdit,
+		"delete_plan_comment": b.PlanCommentDelete,
+		"promote_leader":      b.UserPromote,
+		"demote_leader":       b.UserDemote,
+		"become_leader":       b.UserPromoteSelf,
+		"spectator_toggle":    b.UserSpectatorToggle,
+		"revise_battle":       b.Revise,
+		"concede_battle":      b.Delete,
+		"abandon_battle":      b.Abandon,
 	}
 
 	go h.run()
+	go b.watchAsyncDeadlines()
 
 	return b
 }
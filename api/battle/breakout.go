@@ -0,0 +1,137 @@
+package battle
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+)
+
+// breakoutLeaderOperations contains the leader operations a breakout sub-leader can execute on their breakouts plans
+var breakoutLeaderOperations = map[string]struct{}{
+	"activate_plan": {},
+	"revote_plan":   {},
+	"skip_plan":     {},
+	"end_voting":    {},
+	"finalize_plan": {},
+}
+
+// breakoutsEvent the value structure used for breakouts_updated socket messages
+type breakoutsEvent struct {
+	Breakouts []*model.BattleBreakout `json:"breakouts"`
+	Plans     []*model.Plan           `json:"plans"`
+	Users     []*model.BattleUser     `json:"users"`
+}
+
+// eventPlanID gets the plan ID from an event value that's either the plan ID itself or an object with a planId
+func eventPlanID(EventValue string) string {
+	var v struct {
+		PlanID string `json:"planId"`
+	}
+	if err := json.Unmarshal([]byte(EventValue), &v); err == nil {
+		return v.PlanID
+	}
+
+	return EventValue
+}
+
+// validBreakouts checks there are at least two breakouts each with a name, plans and a sub-leader among its users,
+// and that no user or plan is in more than one breakout
+func validBreakouts(Breakouts []*model.BattleBreakout, BattleUsers []*model.BattleUser) bool {
+	if len(Breakouts) < 2 {
+		return false
+	}
+
+	var battleUsers = make(map[string]bool)
+	for _, u := range BattleUsers {
+		battleUsers[u.Id] = true
+	}
+	var users = make(map[string]bool)
+	var plans = make(map[string]bool)
+
+	for _, bo := range Breakouts {
+		if bo.Name == "" || len(bo.Name) > 256 || len(bo.PlanIds) == 0 || !contains(bo.UserIds, bo.LeaderId) {
+			return false
+		}
+		for _, UserID := range bo.UserIds {
+			if users[UserID] || !battleUsers[UserID] {
+				return false
+			}
+			users[UserID] = true
+		}
+		for _, PlanID := range bo.PlanIds {
+			if plans[PlanID] {
+				return false
+			}
+			plans[PlanID] = true
+		}
+	}
+
+	return true
+}
+
+// contains checks if a string is present in a slice
+func contains(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StartBreakouts handles splitting the battles users and plans into parallel breakout groups,
+// each breakout votes on its own plans with its own active plan under its sub-leader
+func (b *Service) StartBreakouts(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var Breakouts []*model.BattleBreakout
+	if err := json.Unmarshal([]byte(EventValue), &Breakouts); err != nil {
+		return nil, err, false
+	}
+
+	if !validBreakouts(Breakouts, b.db.GetBattleUsers(BattleID)) {
+		return nil, errors.New("INVALID_BREAKOUTS"), false
+	}
+
+	Breakouts, err := b.db.StartBattleBreakouts(BattleID, Breakouts)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updated, _ := json.Marshal(breakoutsEvent{
+		Breakouts: Breakouts,
+		Plans:     b.db.GetPlans(BattleID, ""),
+		Users:     b.db.GetBattleUsers(BattleID),
+	})
+	msg := createSocketEvent("breakouts_updated", string(updated), "")
+
+	return msg, nil, false
+}
+
+// EndBreakouts handles merging the breakout groups and their results back into the battle
+func (b *Service) EndBreakouts(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	plans, err := b.db.EndBattleBreakouts(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updated, _ := json.Marshal(breakoutsEvent{
+		Breakouts: make([]*model.BattleBreakout, 0),
+		Plans:     plans,
+		Users:     b.db.GetBattleUsers(BattleID),
+	})
+	msg := createSocketEvent("breakouts_updated", string(updated), "")
+
+	return msg, nil, false
+}
+
+// planInBreakout checks whether the plan belongs to a breakout group
+func planInBreakout(Plans []*model.Plan, PlanID string) bool {
+	for _, p := range Plans {
+		if p.Id == PlanID {
+			return p.BreakoutId != ""
+		}
+	}
+
+	return false
+}
@@ -2,6 +2,7 @@ package battle
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -28,18 +29,23 @@ const (
 
 // leaderOnlyOperations contains a map of operations that only a battle leader can execute
 var leaderOnlyOperations = map[string]struct{}{
-	"add_plan":       {},
-	"revise_plan":    {},
-	"burn_plan":      {},
-	"activate_plan":  {},
-	"skip_plan":      {},
-	"end_voting":     {},
-	"finalize_plan":  {},
-	"jab_warrior":    {},
-	"promote_leader": {},
-	"demote_leader":  {},
-	"revise_battle":  {},
-	"concede_battle": {},
+	"add_plan":           {},
+	"revise_plan":        {},
+	"burn_plan":          {},
+	"reorder_plans":      {},
+	"activate_plan":      {},
+	"revote_plan":        {},
+	"start_async_voting": {},
+	"start_breakouts":    {},
+	"end_breakouts":      {},
+	"skip_plan":          {},
+	"end_voting":         {},
+	"finalize_plan":      {},
+	"jab_warrior":        {},
+	"promote_leader":     {},
+	"demote_leader":      {},
+	"revise_battle":      {},
+	"concede_battle":     {},
 }
 
 var upgrader = websocket.Upgrader{
@@ -56,6 +62,24 @@ type connection struct {
 	send chan []byte
 }
 
+// confirmEventLeader confirms the user can execute the event when it's a leader only operation,
+// breakout sub-leaders can execute the voting operations on their own breakouts plans
+func (b *Service) confirmEventLeader(BattleID string, UserID string, EventType string, EventValue string) error {
+	if _, ok := leaderOnlyOperations[EventType]; !ok {
+		return nil
+	}
+	if err := b.db.ConfirmLeader(BattleID, UserID); err == nil {
+		return nil
+	}
+	if _, ok := breakoutLeaderOperations[EventType]; ok {
+		if err := b.db.ConfirmBreakoutLeader(BattleID, UserID, eventPlanID(EventValue)); err == nil {
+			return nil
+		}
+	}
+
+	return errors.New("REQUIRES_BATTLE_LEADER")
+}
+
 // readPump pumps messages from the websocket connection to the hub.
 func (sub subscription) readPump(b *Service) {
 	var forceClosed bool
@@ -70,6 +94,7 @@ func (sub subscription) readPump(b *Service) {
 		retreatEvent := createSocketEvent("warrior_retreated", string(UpdatedUsers), UserID)
 		m := message{retreatEvent, BattleID}
 		h.broadcast <- m
+		b.leaderDeparted(BattleID, UserID)
 
 		h.unregister <- sub
 		if forceClosed {
@@ -104,11 +129,8 @@ func (sub subscription) readPump(b *Service) {
 		eventValue := keyVal["value"]
 
 		// confirm leader for any operation that requires it
-		if _, ok := leaderOnlyOperations[eventType]; ok {
-			err := b.db.ConfirmLeader(BattleID, UserID)
-			if err != nil {
-				badEvent = true
-			}
+		if err := b.confirmEventLeader(BattleID, UserID, eventType, eventValue); err != nil {
+			badEvent = true
 		}
 
 		// find event handler and execute otherwise invalid event
@@ -124,7 +146,8 @@ func (sub subscription) readPump(b *Service) {
 			}
 		}
 
-		if !badEvent {
+		// handlers that broadcast their own events return no message
+		if !badEvent && msg != nil {
 			m := message{msg, sub.arena}
 			h.broadcast <- m
 		}
@@ -194,6 +217,12 @@ func (b *Service) ServeBattleWs() http.HandlerFunc {
 		}
 		c := &connection{send: make(chan []byte, 256), ws: ws}
 
+		// observers authenticate with a read-only token instead of a user session
+		if ObserverToken := r.URL.Query().Get("observerToken"); ObserverToken != "" {
+			b.serveObserver(c, battleID, ObserverToken)
+			return
+		}
+
 		SessionId, cookieErr := b.validateSessionCookie(w, r)
 		if cookieErr != nil && cookieErr.Error() != "NO_SESSION_COOKIE" {
 			b.handleSocketClose(ws, 4001, "unauthorized")
@@ -276,6 +305,7 @@ func (b *Service) ServeBattleWs() http.HandlerFunc {
 				h.register <- ss
 
 				Users, _ := b.db.AddUserToBattle(ss.arena, User.Id)
+				b.leaderReturned(ss.arena, User.Id)
 				UpdatedUsers, _ := json.Marshal(Users)
 
 				Battle, _ := json.Marshal(battle)
@@ -299,11 +329,8 @@ func (b *Service) ServeBattleWs() http.HandlerFunc {
 func (b *Service) APIEvent(arenaID string, UserID, eventType string, eventValue string) error {
 
 	// confirm leader for any operation that requires it
-	if _, ok := leaderOnlyOperations[eventType]; ok {
-		err := b.db.ConfirmLeader(arenaID, UserID)
-		if err != nil {
-			return err
-		}
+	if err := b.confirmEventLeader(arenaID, UserID, eventType, eventValue); err != nil {
+		return err
 	}
 
 	// find event handler and execute otherwise invalid event
@@ -313,7 +340,7 @@ func (b *Service) APIEvent(arenaID string, UserID, eventType string, eventValue
 			return eventErr
 		}
 
-		if _, ok := h.arenas[arenaID]; ok {
+		if h.arenaActive(arenaID) && msg != nil {
 			m := message{msg, arenaID}
 			h.broadcast <- m
 		}
@@ -321,3 +348,11 @@ func (b *Service) APIEvent(arenaID string, UserID, eventType string, eventValue
 
 	return nil
 }
+
+// BroadcastEvent sends an event to the arena (if active) for changes made outside of an event handler
+func (b *Service) BroadcastEvent(arenaID string, eventType string, eventValue string) {
+	if h.arenaActive(arenaID) {
+		m := message{createSocketEvent(eventType, eventValue, ""), arenaID}
+		h.broadcast <- m
+	}
+}
@@ -3,6 +3,10 @@ package battle
 import (
 	"encoding/json"
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
 )
 
 // UserNudge handles notifying user that they need to vote
@@ -12,6 +16,12 @@ func (b *Service) UserNudge(BattleID string, UserID string, EventValue string) (
 	return msg, nil, false
 }
 
+// the range of the optional confidence level (fist of five) users can attach to their vote
+const (
+	minVoteConfidence = 1
+	maxVoteConfidence = 5
+)
+
 // UserVote handles the participants vote event by setting their vote
 // and checks if AutoFinishVoting && AllVoted if so ends voting
 func (b *Service) UserVote(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
@@ -20,21 +30,72 @@ func (b *Service) UserVote(BattleID string, UserID string, EventValue string) ([
 		VoteValue        string `json:"voteValue"`
 		PlanID           string `json:"planId"`
 		AutoFinishVoting bool   `json:"autoFinishVoting"`
+		Dimension        string `json:"dimension"`
+		Confidence       *int   `json:"confidence"`
 	}
 	json.Unmarshal([]byte(EventValue), &wv)
 
-	Plans, AllVoted := b.db.SetVote(BattleID, UserID, wv.PlanID, wv.VoteValue)
+	if wv.Confidence != nil && (*wv.Confidence < minVoteConfidence || *wv.Confidence > maxVoteConfidence) {
+		return nil, errors.New("INVALID_VOTE_CONFIDENCE"), false
+	}
+
+	PointValuesAllowed, err := b.db.GetBattlePointValuesAllowed(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+	// battles with estimation dimensions are voted on per dimension with the dimensions own values
+	Dimensions, _, err := b.db.GetBattleDimensions(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+	if len(Dimensions) > 0 || wv.Dimension != "" {
+		PointValuesAllowed = nil
+		for _, dim := range Dimensions {
+			if dim.Key == wv.Dimension {
+				PointValuesAllowed = dim.PointValuesAllowed
+			}
+		}
+		if PointValuesAllowed == nil {
+			return nil, errors.New("INVALID_VOTE_DIMENSION"), false
+		}
+	}
+	if !validVoteValue(PointValuesAllowed, wv.VoteValue) {
+		return nil, errors.New("INVALID_VOTE_VALUE"), false
+	}
+
+	if err := b.db.ConfirmPlanActive(BattleID, wv.PlanID); err != nil {
+		return nil, err, false
+	}
+	// during breakouts each group only votes on its own plans
+	if err := b.db.ConfirmBreakoutVoter(BattleID, UserID, wv.PlanID); err != nil {
+		return nil, err, false
+	}
+	VotingMode, err := b.db.GetBattleVotingMode(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+
+	Plans, AllVoted := b.db.SetVote(BattleID, UserID, wv.PlanID, wv.VoteValue, wv.Dimension, wv.Confidence)
 
 	updatedPlans, _ := json.Marshal(Plans)
 	msg = createSocketEvent("vote_activity", string(updatedPlans), UserID)
 
-	if AllVoted && wv.AutoFinishVoting {
+	// async voting plans close as soon as everyone has voted rather than waiting on the leader
+	if AllVoted && (wv.AutoFinishVoting || VotingMode == "async") {
+		b.stopPlanVotingTimer(BattleID, wv.PlanID)
 		plans, err := b.db.EndPlanVoting(BattleID, wv.PlanID)
 		if err != nil {
 			return nil, err, false
 		}
 		updatedPlans, _ := json.Marshal(plans)
 		msg = createSocketEvent("voting_ended", string(updatedPlans), "")
+
+		// the summary has to follow voting_ended so it's broadcast here rather than returned
+		if VotingMode == "async" && asyncVotingClosed(plans) {
+			b.BroadcastEvent(BattleID, "voting_ended", string(updatedPlans))
+			go b.sendAsyncVotingSummary(BattleID, plans)
+			return nil, nil, false
+		}
 	}
 
 	return msg, nil, false
@@ -122,6 +183,7 @@ func (b *Service) UserSpectatorToggle(BattleID string, UserID string, EventValue
 
 // PlanVoteEnd handles ending plan voting
 func (b *Service) PlanVoteEnd(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	b.stopPlanVotingTimer(BattleID, EventValue)
 	plans, err := b.db.EndPlanVoting(BattleID, EventValue)
 	if err != nil {
 		return nil, err, false
@@ -135,21 +197,52 @@ func (b *Service) PlanVoteEnd(BattleID string, UserID string, EventValue string)
 // Revise handles editing the battle settings
 func (b *Service) Revise(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
 	var rb struct {
-		BattleName           string   `json:"battleName"`
-		PointValuesAllowed   []string `json:"pointValuesAllowed"`
-		AutoFinishVoting     bool     `json:"autoFinishVoting"`
-		PointAverageRounding string   `json:"pointAverageRounding"`
-		JoinCode             string   `json:"joinCode"`
-		LeaderCode           string   `json:"leaderCode"`
+		BattleName           string                   `json:"battleName"`
+		PointValuesAllowed   []string                 `json:"pointValuesAllowed"`
+		AutoFinishVoting     bool                     `json:"autoFinishVoting"`
+		PointAverageRounding string                   `json:"pointAverageRounding"`
+		VotingTimerSeconds   *int                     `json:"votingTimerSeconds"`
+		EstimationScaleID    *string                  `json:"estimationScaleId"`
+		JoinCode             string                   `json:"joinCode"`
+		LeaderCode           string                   `json:"leaderCode"`
+		Dimensions           []*model.BattleDimension `json:"dimensions"`
+		DimensionFormula     string                   `json:"dimensionFormula"`
 	}
 	json.Unmarshal([]byte(EventValue), &rb)
 
+	// the voting timer and estimation scale are kept when not provided
+	if rb.VotingTimerSeconds != nil && *rb.VotingTimerSeconds < 0 {
+		return nil, errors.New("INVALID_VOTING_TIMER"), false
+	}
+
+	if rb.EstimationScaleID != nil && *rb.EstimationScaleID != "" {
+		if err := b.db.ConfirmEstimationScaleAccess(*rb.EstimationScaleID, UserID); err != nil {
+			return nil, err, false
+		}
+	}
+
+	// dimensions are only revised when provided
+	if rb.Dimensions != nil {
+		for _, dim := range rb.Dimensions {
+			if dim.EstimationScaleID != "" {
+				if err := b.db.ConfirmEstimationScaleAccess(dim.EstimationScaleID, UserID); err != nil {
+					return nil, err, false
+				}
+			}
+		}
+		if err := b.db.PrepareBattleDimensions(rb.Dimensions, rb.DimensionFormula); err != nil {
+			return nil, err, false
+		}
+	}
+
 	err := b.db.ReviseBattle(
 		BattleID,
 		rb.BattleName,
 		rb.PointValuesAllowed,
 		rb.AutoFinishVoting,
 		rb.PointAverageRounding,
+		rb.VotingTimerSeconds,
+		rb.EstimationScaleID,
 		rb.JoinCode,
 		rb.LeaderCode,
 	)
@@ -157,6 +250,33 @@ func (b *Service) Revise(BattleID string, UserID string, EventValue string) ([]b
 		return nil, err, false
 	}
 
+	// point values are taken from the estimation scale when one is referenced
+	PointValuesAllowed, err := b.db.GetBattlePointValuesAllowed(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+	rb.PointValuesAllowed = PointValuesAllowed
+
+	VotingTimerSeconds, err := b.db.GetBattleVotingTimer(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+	rb.VotingTimerSeconds = &VotingTimerSeconds
+
+	EstimationScaleID, err := b.db.GetBattleEstimationScaleID(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+	rb.EstimationScaleID = &EstimationScaleID
+
+	if rb.Dimensions != nil {
+		if _, err := b.db.SetBattleDimensions(BattleID, rb.Dimensions, rb.DimensionFormula); err != nil {
+			return nil, err, false
+		}
+	} else if rb.Dimensions, rb.DimensionFormula, err = b.db.GetBattleDimensions(BattleID); err != nil {
+		return nil, err, false
+	}
+
 	rb.LeaderCode = ""
 
 	updatedBattle, _ := json.Marshal(rb)
@@ -171,6 +291,7 @@ func (b *Service) Delete(BattleID string, UserID string, EventValue string) ([]b
 	if err != nil {
 		return nil, err, false
 	}
+	b.stopVotingTimer(BattleID)
 	msg := createSocketEvent("battle_conceded", "", "")
 
 	return msg, nil, false
@@ -227,18 +348,80 @@ func (b *Service) PlanDelete(BattleID string, UserID string, EventValue string)
 	if err != nil {
 		return nil, err, false
 	}
+	b.stopPlanVotingTimer(BattleID, EventValue)
 	updatedPlans, _ := json.Marshal(plans)
 	msg := createSocketEvent("plan_burned", string(updatedPlans), "")
 
 	return msg, nil, false
 }
 
+// PlansReorder handles setting the order of the battles plans
+func (b *Service) PlansReorder(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var ro struct {
+		PlanIDs []string `json:"planIds"`
+	}
+	if err := json.Unmarshal([]byte(EventValue), &ro); err != nil {
+		return nil, err, false
+	}
+
+	plans, err := b.db.ReorderPlans(BattleID, ro.PlanIDs)
+	if err != nil {
+		return nil, err, false
+	}
+	updatedPlans, _ := json.Marshal(plans)
+	msg := createSocketEvent("plans_reordered", string(updatedPlans), "")
+
+	return msg, nil, false
+}
+
 // PlanActivate handles activating a plan for voting
+// and starts the voting countdown when the battle has a voting timer
 func (b *Service) PlanActivate(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	VotingTimerSeconds, err := b.db.GetBattleVotingTimer(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+
 	plans, err := b.db.ActivatePlanVoting(BattleID, EventValue)
 	if err != nil {
 		return nil, err, false
 	}
+
+	// voting timers only run for the battles own plans, not those of a breakout
+	if planInBreakout(plans, EventValue) {
+		b.stopPlanVotingTimer(BattleID, EventValue)
+	} else if VotingTimerSeconds > 0 {
+		b.startVotingTimer(BattleID, EventValue, time.Duration(VotingTimerSeconds)*time.Second)
+	} else {
+		b.stopVotingTimer(BattleID)
+	}
+	updatedPlans, _ := json.Marshal(plans)
+	msg := createSocketEvent("plan_activated", string(updatedPlans), "")
+
+	return msg, nil, false
+}
+
+// PlanRevote handles opening a fresh voting round for a plan that was already voted on,
+// previous rounds are kept in the plans round history
+func (b *Service) PlanRevote(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	VotingTimerSeconds, err := b.db.GetBattleVotingTimer(BattleID)
+	if err != nil {
+		return nil, err, false
+	}
+
+	plans, err := b.db.RevotePlan(BattleID, EventValue)
+	if err != nil {
+		return nil, err, false
+	}
+
+	// voting timers only run for the battles own plans, not those of a breakout
+	if planInBreakout(plans, EventValue) {
+		b.stopPlanVotingTimer(BattleID, EventValue)
+	} else if VotingTimerSeconds > 0 {
+		b.startVotingTimer(BattleID, EventValue, time.Duration(VotingTimerSeconds)*time.Second)
+	} else {
+		b.stopVotingTimer(BattleID)
+	}
 	updatedPlans, _ := json.Marshal(plans)
 	msg := createSocketEvent("plan_activated", string(updatedPlans), "")
 
@@ -247,6 +430,7 @@ func (b *Service) PlanActivate(BattleID string, UserID string, EventValue string
 
 // PlanSkip handles skipping a plan voting
 func (b *Service) PlanSkip(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	b.stopPlanVotingTimer(BattleID, EventValue)
 	plans, err := b.db.SkipPlan(BattleID, EventValue)
 	if err != nil {
 		return nil, err, false
@@ -260,21 +444,93 @@ func (b *Service) PlanSkip(BattleID string, UserID string, EventValue string) ([
 // PlanFinalize handles setting a plan point value
 func (b *Service) PlanFinalize(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
 	var p struct {
-		Id     string `json:"planId"`
-		Points string `json:"planPoints"`
+		Id              string            `json:"planId"`
+		Points          string            `json:"planPoints"`
+		DimensionPoints map[string]string `json:"dimensionPoints"`
 	}
 	json.Unmarshal([]byte(EventValue), &p)
 
-	plans, err := b.db.FinalizePlan(BattleID, p.Id, p.Points)
+	plans, err := b.db.FinalizePlan(BattleID, p.Id, p.Points, p.DimensionPoints)
 	if err != nil {
 		return nil, err, false
 	}
+	b.stopPlanVotingTimer(BattleID, p.Id)
 	updatedPlans, _ := json.Marshal(plans)
 	msg := createSocketEvent("plan_finalized", string(updatedPlans), "")
 
 	return msg, nil, false
 }
 
+// PlanCommentAdd handles adding a comment to a battle plan
+func (b *Service) PlanCommentAdd(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var pc struct {
+		PlanID  string `json:"planId"`
+		Comment string `json:"comment"`
+	}
+	json.Unmarshal([]byte(EventValue), &pc)
+
+	if strings.TrimSpace(pc.Comment) == "" {
+		return nil, errors.New("INVALID_PLAN_COMMENT"), false
+	}
+
+	plans, err := b.db.AddPlanComment(BattleID, UserID, pc.PlanID, pc.Comment)
+	if err != nil {
+		return nil, err, false
+	}
+	updatedPlans, _ := json.Marshal(plans)
+	msg := createSocketEvent("plan_revised", string(updatedPlans), "")
+
+	return msg, nil, false
+}
+
+// PlanCommentEdit handles editing a battle plan comment, only the comments author can edit it
+func (b *Service) PlanCommentEdit(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var pc struct {
+		CommentID string `json:"commentId"`
+		Comment   string `json:"comment"`
+	}
+	json.Unmarshal([]byte(EventValue), &pc)
+
+	if strings.TrimSpace(pc.Comment) == "" {
+		return nil, errors.New("INVALID_PLAN_COMMENT"), false
+	}
+	if err := b.db.ConfirmPlanCommentAuthor(BattleID, pc.CommentID, UserID); err != nil {
+		return nil, err, false
+	}
+
+	plans, err := b.db.EditPlanComment(BattleID, pc.CommentID, pc.Comment)
+	if err != nil {
+		return nil, err, false
+	}
+	updatedPlans, _ := json.Marshal(plans)
+	msg := createSocketEvent("plan_revised", string(updatedPlans), "")
+
+	return msg, nil, false
+}
+
+// PlanCommentDelete handles deleting a battle plan comment, either by its author or a battle leader
+func (b *Service) PlanCommentDelete(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var pc struct {
+		CommentID string `json:"commentId"`
+	}
+	json.Unmarshal([]byte(EventValue), &pc)
+
+	if err := b.db.ConfirmPlanCommentAuthor(BattleID, pc.CommentID, UserID); err != nil {
+		if leaderErr := b.db.ConfirmLeader(BattleID, UserID); leaderErr != nil {
+			return nil, err, false
+		}
+	}
+
+	plans, err := b.db.DeletePlanComment(BattleID, pc.CommentID)
+	if err != nil {
+		return nil, err, false
+	}
+	updatedPlans, _ := json.Marshal(plans)
+	msg := createSocketEvent("plan_revised", string(updatedPlans), "")
+
+	return msg, nil, false
+}
+
 // Abandon handles setting abandoned true so battle doesn't show up in users battle list, then leaves battle
 func (b *Service) Abandon(BattleID string, UserID string, EventValue string) ([]byte, error, bool) {
 	b.db.AbandonBattle(BattleID, UserID)
@@ -300,3 +556,14 @@ func createSocketEvent(Type string, Value string, User string) []byte {
 
 	return event
 }
+
+// validVoteValue checks the vote is one of the battles allowed point values
+func validVoteValue(PointValuesAllowed []string, VoteValue string) bool {
+	for _, v := range PointValuesAllowed {
+		if v == VoteValue {
+			return true
+		}
+	}
+
+	return false
+}
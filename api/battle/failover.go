@@ -0,0 +1,84 @@
+package battle
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// leaderFailoverPromote the failover policy promoting the longest connected participant when no leaders remain,
+// the none policy leaves the battle leaderless announcing a leader is needed
+const leaderFailoverPromote = "promote"
+
+// leaderDeparted checks whether the departing user was the battles last connected leader
+// and if so schedules the leader failover after the grace period, restarting any pending one
+func (b *Service) leaderDeparted(BattleID string, UserID string) {
+	if err := b.db.ConfirmLeader(BattleID, UserID); err != nil {
+		return
+	}
+
+	b.failoversMu.Lock()
+	defer b.failoversMu.Unlock()
+
+	if pending, ok := b.failovers[BattleID]; ok {
+		pending.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(b.leaderFailoverGracePeriod, func() {
+		b.failoversMu.Lock()
+		// a leader departing again replaces the timer, only the current one fails over
+		if b.failovers[BattleID] != timer {
+			b.failoversMu.Unlock()
+			return
+		}
+		delete(b.failovers, BattleID)
+		b.failoversMu.Unlock()
+
+		b.leaderFailover(BattleID)
+	})
+	b.failovers[BattleID] = timer
+}
+
+// leaderReturned cancels the battles pending leader failover when a leader reconnects
+func (b *Service) leaderReturned(BattleID string, UserID string) {
+	if err := b.db.ConfirmLeader(BattleID, UserID); err != nil {
+		return
+	}
+
+	b.failoversMu.Lock()
+	defer b.failoversMu.Unlock()
+
+	if pending, ok := b.failovers[BattleID]; ok {
+		pending.Stop()
+		delete(b.failovers, BattleID)
+	}
+}
+
+// leaderFailover applies the failover policy when the battle still has no connected leaders
+func (b *Service) leaderFailover(BattleID string) {
+	if !h.arenaActive(BattleID) {
+		return
+	}
+
+	count, err := b.db.GetBattleActiveLeaderCount(BattleID)
+	if err != nil || count > 0 {
+		return
+	}
+
+	if b.leaderFailoverPolicy == leaderFailoverPromote {
+		if UserID, err := b.db.GetBattleFailoverUser(BattleID); err == nil {
+			leaders, err := b.db.SetBattleLeader(BattleID, UserID)
+			if err != nil {
+				b.logger.Error("leader failover promote error", zap.Error(err))
+				return
+			}
+			leadersJson, _ := json.Marshal(leaders)
+			b.BroadcastEvent(BattleID, "leaders_updated", string(leadersJson))
+			return
+		}
+	}
+
+	b.BroadcastEvent(BattleID, "leader_needed", "")
+}
@@ -5,6 +5,12 @@ type message struct {
 	arena string
 }
 
+// arenaQuery asks the hub whether the arena has connections
+type arenaQuery struct {
+	arena  string
+	active chan bool
+}
+
 type subscription struct {
 	conn   *connection
 	arena  string
@@ -25,12 +31,16 @@ type hub struct {
 
 	// Unregister requests from connections.
 	unregister chan subscription
+
+	// Active arena requests from outside the hub.
+	query chan arenaQuery
 }
 
 var h = hub{
 	broadcast:  make(chan message),
 	register:   make(chan subscription),
 	unregister: make(chan subscription),
+	query:      make(chan arenaQuery),
 	arenas:     make(map[string]map[*connection]struct{}),
 }
 
@@ -55,6 +65,9 @@ func (h *hub) run() {
 					}
 				}
 			}
+		case q := <-h.query:
+			_, ok := h.arenas[q.arena]
+			q.active <- ok
 		case m := <-h.broadcast:
 			connections := h.arenas[m.arena]
 			for c := range connections {
@@ -71,3 +84,11 @@ func (h *hub) run() {
 		}
 	}
 }
+
+// arenaActive asks the hub whether the arena has connections, safe to call from any goroutine
+func (h *hub) arenaActive(arena string) bool {
+	q := arenaQuery{arena, make(chan bool, 1)}
+	h.query <- q
+
+	return <-q.active
+}
@@ -0,0 +1,70 @@
+package battle
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// observerEntityType the observer token entity type for battles
+const observerEntityType = "battle"
+
+// serveObserver subscribes a read-only observer to the battle, observers receive every broadcast
+// but are never added as a battle user and any events they send are dropped
+func (b *Service) serveObserver(c *connection, BattleID string, ObserverToken string) {
+	if err := b.db.ConfirmObserverToken(observerEntityType, BattleID, ObserverToken); err != nil {
+		b.handleSocketClose(c.ws, 4001, "unauthorized")
+		return
+	}
+
+	battle, battleErr := b.db.GetBattle(BattleID, "")
+	if battleErr != nil {
+		b.handleSocketClose(c.ws, 4004, "battle not found")
+		return
+	}
+	battle.JoinCode = ""
+	battle.LeaderCode = ""
+
+	ss := subscription{c, BattleID, ""}
+	h.register <- ss
+
+	Battle, _ := json.Marshal(battle)
+	initEvent := createSocketEvent("init", string(Battle), "")
+	_ = c.write(websocket.TextMessage, initEvent)
+
+	go ss.writePump()
+	go ss.observerReadPump(b, ObserverToken)
+}
+
+// observerReadPump keeps the observer connection open until it closes or its token is revoked
+func (sub subscription) observerReadPump(b *Service, ObserverToken string) {
+	c := sub.conn
+
+	defer func() {
+		h.unregister <- sub
+		if err := c.ws.Close(); err != nil {
+			b.logger.Error("close error", zap.Error(err))
+		}
+	}()
+	c.ws.SetReadLimit(maxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		if err := b.db.ConfirmObserverToken(observerEntityType, sub.arena, ObserverToken); err != nil {
+			return errors.New("OBSERVER_TOKEN_REVOKED")
+		}
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+				b.logger.Error("unexpected close error", zap.Error(err))
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package battle
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// votingTimer is a running voting countdown for a battles active plan
+type votingTimer struct {
+	planID string
+	cancel chan struct{}
+}
+
+// timerEvent is the value structure used for timer socket messages
+type timerEvent struct {
+	PlanID    string    `json:"planId"`
+	Duration  int       `json:"duration,omitempty"`
+	Remaining int       `json:"remaining"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// startVotingTimer starts a voting countdown for the plan replacing any running countdown for the battle,
+// the countdown is owned by the server and not a connection so voting still ends if the leader disconnects
+func (b *Service) startVotingTimer(BattleID string, PlanID string, Duration time.Duration) {
+	t := &votingTimer{
+		planID: PlanID,
+		cancel: make(chan struct{}),
+	}
+
+	b.timersMu.Lock()
+	if running, ok := b.timers[BattleID]; ok {
+		close(running.cancel)
+	}
+	b.timers[BattleID] = t
+	b.timersMu.Unlock()
+
+	go b.runVotingTimer(BattleID, t, Duration)
+}
+
+// stopVotingTimer stops the battles running voting countdown if any
+func (b *Service) stopVotingTimer(BattleID string) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+
+	if t, ok := b.timers[BattleID]; ok {
+		close(t.cancel)
+		delete(b.timers, BattleID)
+	}
+}
+
+// stopPlanVotingTimer stops the battles running voting countdown only if it belongs to the plan
+func (b *Service) stopPlanVotingTimer(BattleID string, PlanID string) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+
+	if t, ok := b.timers[BattleID]; ok && t.planID == PlanID {
+		close(t.cancel)
+		delete(b.timers, BattleID)
+	}
+}
+
+// runVotingTimer broadcasts the countdown every second and ends the plan voting once it runs out
+func (b *Service) runVotingTimer(BattleID string, t *votingTimer, Duration time.Duration) {
+	EndTime := time.Now().Add(Duration)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	startedEvent, _ := json.Marshal(timerEvent{
+		PlanID:    t.planID,
+		Duration:  int(Duration.Seconds()),
+		Remaining: int(Duration.Seconds()),
+		EndTime:   EndTime,
+	})
+	h.broadcast <- message{createSocketEvent("timer_started", string(startedEvent), ""), BattleID}
+
+	for {
+		select {
+		case <-t.cancel:
+			return
+		case now := <-ticker.C:
+			remaining := EndTime.Sub(now).Round(time.Second)
+			if remaining > 0 {
+				tickEvent, _ := json.Marshal(timerEvent{
+					PlanID:    t.planID,
+					Remaining: int(remaining.Seconds()),
+					EndTime:   EndTime,
+				})
+				h.broadcast <- message{createSocketEvent("timer_tick", string(tickEvent), ""), BattleID}
+				continue
+			}
+
+			// the countdown may have been stopped or replaced while waiting on the ticker
+			b.timersMu.Lock()
+			if b.timers[BattleID] != t {
+				b.timersMu.Unlock()
+				return
+			}
+			delete(b.timers, BattleID)
+			b.timersMu.Unlock()
+
+			plans, err := b.db.EndPlanVoting(BattleID, t.planID)
+			if err != nil {
+				b.logger.Error("voting timer end voting error", zap.Error(err))
+				return
+			}
+			updatedPlans, _ := json.Marshal(plans)
+			h.broadcast <- message{createSocketEvent("voting_ended", string(updatedPlans), ""), BattleID}
+
+			return
+		}
+	}
+}
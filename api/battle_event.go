@@ -0,0 +1,513 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/api/battle"
+	"github.com/gorilla/mux"
+)
+
+// battleEvent sends the event to the battle service as the requesting user, which broadcasts the result to connected clients
+func (a *api) battleEvent(w http.ResponseWriter, r *http.Request, b *battle.Service, BattleID string, EventType string, EventValue string) {
+	UserID := r.Context().Value(contextKeyUserID).(string)
+
+	if err := b.APIEvent(BattleID, UserID, EventType, EventValue); err != nil {
+		if err.Error() == "REQUIRES_BATTLE_LEADER" {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, err.Error()))
+			return
+		}
+		a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+		return
+	}
+
+	a.Success(w, r, http.StatusOK, nil, nil)
+}
+
+// battlePlanEvent builds the event value from the request body adding the planId from the route
+func battlePlanEvent(r *http.Request) (string, error) {
+	var event = make(map[string]interface{})
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &event); err != nil {
+			return "", err
+		}
+	}
+	event["planId"] = mux.Vars(r)["planId"]
+
+	value, _ := json.Marshal(event)
+
+	return string(value), nil
+}
+
+type battleReviseRequestBody struct {
+	BattleName           string   `json:"battleName"`
+	PointValuesAllowed   []string `json:"pointValuesAllowed"`
+	AutoFinishVoting     bool     `json:"autoFinishVoting"`
+	PointAverageRounding string   `json:"pointAverageRounding"`
+	VotingTimerSeconds   *int     `json:"votingTimerSeconds"`
+	EstimationScaleID    *string  `json:"estimationScaleId"`
+	JoinCode             string   `json:"joinCode"`
+	LeaderCode           string   `json:"leaderCode"`
+}
+
+// handleBattleRevise handles revising the battle settings
+// @Summary Revise Battle
+// @Description Revises the battle settings, same as the revise_battle websocket event
+// @Param battleId path string true "the battle ID"
+// @Param battle body battleReviseRequestBody true "battle settings object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId} [put]
+func (a *api) handleBattleRevise(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "revise_battle", string(body))
+	}
+}
+
+// handleBattleDelete handles deleting the battle
+// @Summary Delete Battle
+// @Description Deletes the battle, same as the concede_battle websocket event
+// @Param battleId path string true "the battle ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId} [delete]
+func (a *api) handleBattleDelete(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "concede_battle", "")
+	}
+}
+
+// handleBattlePlanRevise handles revising a battle plan
+// @Summary Revise Battle Plan
+// @Description Revises the battle plan, same as the revise_plan websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param plan body planRequestBody true "plan object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId} [put]
+func (a *api) handleBattlePlanRevise(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		EventValue, err := battlePlanEvent(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "revise_plan", EventValue)
+	}
+}
+
+// handleBattlePlanDelete handles deleting a battle plan
+// @Summary Delete Battle Plan
+// @Description Deletes the battle plan, same as the burn_plan websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId} [delete]
+func (a *api) handleBattlePlanDelete(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "burn_plan", vars["planId"])
+	}
+}
+
+// handleBattlePlanActivate handles activating voting on a battle plan
+// @Summary Activate Battle Plan
+// @Description Activates voting on the battle plan, same as the activate_plan websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/activate [post]
+func (a *api) handleBattlePlanActivate(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "activate_plan", vars["planId"])
+	}
+}
+
+// handleBattlePlanRevote handles opening a new voting round on a battle plan
+// @Summary Revote Battle Plan
+// @Description Opens a new voting round on the battle plan, same as the revote_plan websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/revote [post]
+func (a *api) handleBattlePlanRevote(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "revote_plan", vars["planId"])
+	}
+}
+
+// handleBattlePlanSkip handles skipping a battle plan
+// @Summary Skip Battle Plan
+// @Description Skips voting on the battle plan, same as the skip_plan websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/skip [post]
+func (a *api) handleBattlePlanSkip(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "skip_plan", vars["planId"])
+	}
+}
+
+// handleBattlePlanVoteEnd handles ending voting on a battle plan
+// @Summary End Battle Plan Voting
+// @Description Ends voting on the battle plan, same as the end_voting websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/end-voting [post]
+func (a *api) handleBattlePlanVoteEnd(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "end_voting", vars["planId"])
+	}
+}
+
+type planFinalizeRequestBody struct {
+	PlanPoints      string            `json:"planPoints"`
+	DimensionPoints map[string]string `json:"dimensionPoints"`
+}
+
+// handleBattlePlanFinalize handles finalizing the points of a battle plan
+// @Summary Finalize Battle Plan
+// @Description Sets the final points of the battle plan, same as the finalize_plan websocket event,
+// @Description in battles with a dimension formula the points are calculated from the dimension points when not provided
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param points body planFinalizeRequestBody true "plan points object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/finalize [post]
+func (a *api) handleBattlePlanFinalize(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		EventValue, err := battlePlanEvent(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "finalize_plan", EventValue)
+	}
+}
+
+// handleBattlePlanVoteRetract handles retracting the users vote on a battle plan
+// @Summary Retract Battle Plan Vote
+// @Description Retracts the users vote on the battle plan, same as the retract_vote websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/vote [delete]
+func (a *api) handleBattlePlanVoteRetract(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		UserID := r.Context().Value(contextKeyUserID).(string)
+
+		if err := a.db.ConfirmBattleUser(vars["battleId"], UserID); err != nil {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, err.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, vars["battleId"], "retract_vote", vars["planId"])
+	}
+}
+
+type planCommentRequestBody struct {
+	Comment string `json:"comment"`
+}
+
+// handleBattlePlanCommentAdd handles adding a comment to a battle plan
+// @Summary Add Battle Plan Comment
+// @Description Adds a comment to the battle plan, same as the add_plan_comment websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param comment body planCommentRequestBody true "comment object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/comments [post]
+func (a *api) handleBattlePlanCommentAdd(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		UserID := r.Context().Value(contextKeyUserID).(string)
+
+		if err := a.db.ConfirmBattleUser(vars["battleId"], UserID); err != nil {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, err.Error()))
+			return
+		}
+
+		EventValue, err := battlePlanEvent(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, vars["battleId"], "add_plan_comment", EventValue)
+	}
+}
+
+// handleBattlePlanCommentEdit handles editing a battle plan comment
+// @Summary Edit Battle Plan Comment
+// @Description Edits the users comment on the battle plan, same as the edit_plan_comment websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param commentId path string true "the comment ID"
+// @Param comment body planCommentRequestBody true "comment object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/comments/{commentId} [put]
+func (a *api) handleBattlePlanCommentEdit(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		if err := a.db.ConfirmPlanComment(vars["battleId"], vars["planId"], vars["commentId"]); err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var c = planCommentRequestBody{}
+		if jsonErr := json.Unmarshal(body, &c); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		EventValue, _ := json.Marshal(map[string]string{
+			"commentId": vars["commentId"],
+			"comment":   c.Comment,
+		})
+
+		a.battleEvent(w, r, b, vars["battleId"], "edit_plan_comment", string(EventValue))
+	}
+}
+
+// handleBattlePlanCommentDelete handles deleting a battle plan comment
+// @Summary Delete Battle Plan Comment
+// @Description Deletes the comment on the battle plan, same as the delete_plan_comment websocket event
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param commentId path string true "the comment ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/comments/{commentId} [delete]
+func (a *api) handleBattlePlanCommentDelete(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		if err := a.db.ConfirmPlanComment(vars["battleId"], vars["planId"], vars["commentId"]); err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		EventValue, _ := json.Marshal(map[string]string{
+			"commentId": vars["commentId"],
+		})
+
+		a.battleEvent(w, r, b, vars["battleId"], "delete_plan_comment", string(EventValue))
+	}
+}
+
+type asyncVotingRequestBody struct {
+	PlanIDs  []string `json:"planIds"`
+	Deadline string   `json:"deadline" example:"2022-07-15T17:00:00Z"`
+}
+
+// handleBattleAsyncVotingStart handles opening async voting on multiple battle plans
+// @Summary Start Battle Async Voting
+// @Description Opens voting on the plans until the deadline, same as the start_async_voting websocket event
+// @Param battleId path string true "the battle ID"
+// @Param voting body asyncVotingRequestBody true "async voting object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/async-voting [post]
+func (a *api) handleBattleAsyncVotingStart(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "start_async_voting", string(body))
+	}
+}
+
+// handleBattleLeaderAdd handles promoting a battle user to leader
+// @Summary Add Battle Leader
+// @Description Promotes the battle user to leader, same as the promote_leader websocket event
+// @Param battleId path string true "the battle ID"
+// @Param userId path string true "the user ID to promote"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/leaders/{userId} [put]
+func (a *api) handleBattleLeaderAdd(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "promote_leader", vars["userId"])
+	}
+}
+
+// handleBattleLeaderRemove handles demoting a battle leader
+// @Summary Remove Battle Leader
+// @Description Demotes the battle leader, same as the demote_leader websocket event
+// @Param battleId path string true "the battle ID"
+// @Param userId path string true "the user ID to demote"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/leaders/{userId} [delete]
+func (a *api) handleBattleLeaderRemove(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "demote_leader", vars["userId"])
+	}
+}
+
+// handleBattleUserNudge handles nudging a battle user that has yet to vote
+// @Summary Nudge Battle User
+// @Description Nudges the battle user to vote, same as the jab_warrior websocket event
+// @Param battleId path string true "the battle ID"
+// @Param userId path string true "the user ID to nudge"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/users/{userId}/nudge [post]
+func (a *api) handleBattleUserNudge(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.battleEvent(w, r, b, vars["battleId"], "jab_warrior", vars["userId"])
+	}
+}
+
+// handleBattleBreakoutsStart handles splitting the battle into parallel breakout groups
+// @Summary Start Battle Breakouts
+// @Description Splits the battles users and plans into breakout groups each with its own sub-leader, same as the start_breakouts websocket event
+// @Param battleId path string true "the battle ID"
+// @Param breakouts body []model.BattleBreakout true "the breakout groups"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/breakouts [post]
+func (a *api) handleBattleBreakoutsStart(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "start_breakouts", string(body))
+	}
+}
+
+// handleBattleBreakoutsEnd handles merging the breakout groups back into the battle
+// @Summary End Battle Breakouts
+// @Description Merges the breakout groups and their results back into the battle, same as the end_breakouts websocket event
+// @Param battleId path string true "the battle ID"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/breakouts [delete]
+func (a *api) handleBattleBreakoutsEnd(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.battleEvent(w, r, b, mux.Vars(r)["battleId"], "end_breakouts", "")
+	}
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/gorilla/mux"
+)
+
+// battleExportVote a users vote in the battle export
+type battleExportVote struct {
+	UserID     string `json:"userId"`
+	UserName   string `json:"userName"`
+	Vote       string `json:"vote"`
+	Dimension  string `json:"dimension,omitempty"`
+	Confidence *int   `json:"confidence,omitempty"`
+}
+
+// battleExportComment a comment on a plan in the battle export
+type battleExportComment struct {
+	UserID      string    `json:"userId"`
+	UserName    string    `json:"userName"`
+	Comment     string    `json:"comment"`
+	CreatedDate time.Time `json:"createdDate"`
+}
+
+// battleExportPlan a plans results in the battle export
+type battleExportPlan struct {
+	Name            string                 `json:"name"`
+	Type            string                 `json:"type"`
+	ReferenceID     string                 `json:"referenceId"`
+	Link            string                 `json:"link"`
+	Points          string                 `json:"points"`
+	Skipped         bool                   `json:"skipped"`
+	VoteStartTime   time.Time              `json:"voteStartTime"`
+	VoteEndTime     time.Time              `json:"voteEndTime"`
+	DurationSeconds int                    `json:"durationSeconds"`
+	Votes           []*battleExportVote    `json:"votes"`
+	Comments        []*battleExportComment `json:"comments"`
+}
+
+// battleExport the battle results export
+type battleExport struct {
+	BattleID   string              `json:"battleId"`
+	BattleName string              `json:"battleName"`
+	Plans      []*battleExportPlan `json:"plans"`
+}
+
+// buildBattleExport builds the export for the battles plans optionally filtered to finalized or skipped plans
+func buildBattleExport(Battle *model.Battle, Status string) *battleExport {
+	userNames := make(map[string]string)
+	for _, u := range Battle.Users {
+		userNames[u.Id] = u.Name
+	}
+
+	export := &battleExport{
+		BattleID:   Battle.Id,
+		BattleName: Battle.Name,
+		Plans:      make([]*battleExportPlan, 0),
+	}
+
+	for _, p := range Battle.Plans {
+		finalized := p.Points != "" && !p.Skipped
+		if (Status == "finalized" && !finalized) || (Status == "skipped" && !p.Skipped) {
+			continue
+		}
+
+		plan := &battleExportPlan{
+			Name:          p.Name,
+			Type:          p.Type,
+			ReferenceID:   p.ReferenceId,
+			Link:          p.Link,
+			Points:        p.Points,
+			Skipped:       p.Skipped,
+			VoteStartTime: p.VoteStartTime,
+			VoteEndTime:   p.VoteEndTime,
+			Votes:         make([]*battleExportVote, 0, len(p.Votes)),
+			Comments:      make([]*battleExportComment, 0, len(p.Comments)),
+		}
+		if p.VoteEndTime.After(p.VoteStartTime) {
+			plan.DurationSeconds = int(p.VoteEndTime.Sub(p.VoteStartTime).Seconds())
+		}
+		// anonymous voting battles never tie votes to users
+		if !Battle.AnonymousVoting {
+			for _, v := range p.Votes {
+				plan.Votes = append(plan.Votes, &battleExportVote{
+					UserID:     v.UserId,
+					UserName:   userNames[v.UserId],
+					Vote:       v.VoteValue,
+					Dimension:  v.Dimension,
+					Confidence: v.Confidence,
+				})
+			}
+		}
+
+		for _, c := range p.Comments {
+			plan.Comments = append(plan.Comments, &battleExportComment{
+				UserID:      c.UserId,
+				UserName:    userNames[c.UserId],
+				Comment:     c.Comment,
+				CreatedDate: c.CreatedDate,
+			})
+		}
+
+		export.Plans = append(export.Plans, plan)
+	}
+
+	return export
+}
+
+// formatExportVotes formats the plans votes as a single "name: vote" list
+func formatExportVotes(Votes []*battleExportVote) string {
+	votes := make([]string, 0, len(Votes))
+	for _, v := range Votes {
+		vote := v.Vote
+		if v.Dimension != "" {
+			vote = fmt.Sprintf("%s %s", v.Dimension, vote)
+		}
+		if v.Confidence != nil {
+			vote = fmt.Sprintf("%s (confidence %d)", vote, *v.Confidence)
+		}
+		votes = append(votes, fmt.Sprintf("%s: %s", v.UserName, vote))
+	}
+
+	return strings.Join(votes, "; ")
+}
+
+// formatExportComments formats the plans comments as a single "name: comment" list
+func formatExportComments(Comments []*battleExportComment) string {
+	comments := make([]string, 0, len(Comments))
+	for _, c := range Comments {
+		comments = append(comments, fmt.Sprintf("%s: %s", c.UserName, c.Comment))
+	}
+
+	return strings.Join(comments, "; ")
+}
+
+// battleExportCSV writes the battle export as CSV
+func battleExportCSV(export *battleExport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	records := [][]string{{
+		"name", "type", "reference id", "link", "points", "skipped",
+		"vote start", "vote end", "duration seconds", "votes", "comments",
+	}}
+	for _, p := range export.Plans {
+		records = append(records, []string{
+			p.Name,
+			p.Type,
+			p.ReferenceID,
+			p.Link,
+			p.Points,
+			strconv.FormatBool(p.Skipped),
+			p.VoteStartTime.Format(time.RFC3339),
+			p.VoteEndTime.Format(time.RFC3339),
+			strconv.Itoa(p.DurationSeconds),
+			formatExportVotes(p.Votes),
+			formatExportComments(p.Comments),
+		})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// battleExportMarkdown writes the battle export as a markdown table
+func battleExportMarkdown(export *battleExport) []byte {
+	var buf bytes.Buffer
+	cell := strings.NewReplacer("|", "\\|", "\r", "", "\n", " ")
+
+	fmt.Fprintf(&buf, "# %s\n\n", cell.Replace(export.BattleName))
+	buf.WriteString("| Plan | Reference ID | Points | Skipped | Vote Start | Vote End | Duration | Votes | Comments |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, p := range export.Plans {
+		fmt.Fprintf(&buf, "| %s | %s | %s | %t | %s | %s | %s | %s | %s |\n",
+			cell.Replace(p.Name),
+			cell.Replace(p.ReferenceID),
+			cell.Replace(p.Points),
+			p.Skipped,
+			p.VoteStartTime.Format(time.RFC3339),
+			p.VoteEndTime.Format(time.RFC3339),
+			time.Duration(p.DurationSeconds)*time.Second,
+			cell.Replace(formatExportVotes(p.Votes)),
+			cell.Replace(formatExportComments(p.Comments)),
+		)
+	}
+
+	return buf.Bytes()
+}
+
+// handleBattleExport exports the battles plan results
+// @Summary Export Battle
+// @Description Export the battles plans with their points, voting times, individual votes and comments
+// @Tags battle
+// @Produce  json
+// @Produce  text/csv
+// @Produce  text/markdown
+// @Param battleId path string true "the battle ID to export"
+// @Param format query string false "the export format, defaults to json" Enums(json, csv, md)
+// @Param status query string false "only export finalized or skipped plans" Enums(finalized, skipped)
+// @Success 200 object standardJsonResponse{data=battleExport}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/export [get]
+func (a *api) handleBattleExport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		BattleId := vars["battleId"]
+		UserId := r.Context().Value(contextKeyUserID).(string)
+		UserType := r.Context().Value(contextKeyUserType).(string)
+		query := r.URL.Query()
+		Format := query.Get("format")
+		Status := query.Get("status")
+
+		if Status != "" && Status != "finalized" && Status != "skipped" {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_STATUS_FILTER"))
+			return
+		}
+
+		b, err := a.db.GetBattle(BattleId, UserId)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, "BATTLE_NOT_FOUND"))
+			return
+		}
+
+		// don't allow exporting battle details if battle has JoinCode and user hasn't joined yet
+		if b.JoinCode != "" {
+			UserErr := a.db.GetBattleUserActiveStatus(BattleId, UserId)
+			if UserErr != nil && UserType != adminUserType {
+				a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, "USER_MUST_JOIN_BATTLE"))
+				return
+			}
+		}
+
+		export := buildBattleExport(b, Status)
+
+		switch Format {
+		case "", "json":
+			a.Success(w, r, http.StatusOK, export, nil)
+		case "csv":
+			content, err := battleExportCSV(export)
+			if err != nil {
+				a.Failure(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="battle-%s.csv"`, b.Id))
+			w.WriteHeader(http.StatusOK)
+			w.Write(content)
+		case "md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="battle-%s.md"`, b.Id))
+			w.WriteHeader(http.StatusOK)
+			w.Write(battleExportMarkdown(export))
+		default:
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_EXPORT_FORMAT"))
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type battleTemplateRequestBody struct {
+	Name                 string                   `json:"name" validate:"required,max=256"`
+	BattleID             string                   `json:"battleId"`
+	BattleName           string                   `json:"battleName" validate:"max=256"`
+	PointValuesAllowed   []string                 `json:"pointValuesAllowed"`
+	EstimationScaleID    string                   `json:"estimationScaleId"`
+	AutoFinishVoting     bool                     `json:"autoFinishVoting"`
+	PointAverageRounding string                   `json:"pointAverageRounding"`
+	VotingTimerSeconds   int                      `json:"votingTimerSeconds" validate:"min=0"`
+	AnonymousVoting      bool                     `json:"anonymousVoting"`
+	VotingMode           string                   `json:"votingMode" enums:"live,async"`
+	Dimensions           []*model.BattleDimension `json:"dimensions"`
+	DimensionFormula     string                   `json:"dimensionFormula"`
+	Leaders              []string                 `json:"leaders" validate:"dive,uuid"`
+}
+
+// getBattleTemplateForEntity gets the battle template by ID confirming it belongs to the team or user in the route
+func (a *api) getBattleTemplateForEntity(r *http.Request) (*model.BattleTemplate, error) {
+	vars := mux.Vars(r)
+
+	Template, err := a.db.BattleTemplateGet(vars["templateId"])
+	if err != nil {
+		return nil, Errorf(ENOTFOUND, err.Error())
+	}
+
+	if TeamID, ok := vars["teamId"]; ok {
+		if Template.TeamId != TeamID {
+			return nil, Errorf(ENOTFOUND, "BATTLE_TEMPLATE_NOT_FOUND")
+		}
+	} else if Template.TeamId != "" || Template.UserId != vars["userId"] {
+		return nil, Errorf(ENOTFOUND, "BATTLE_TEMPLATE_NOT_FOUND")
+	}
+
+	return Template, nil
+}
+
+// validateBattleTemplateSettings confirms access to the templates estimation scales and validates its voting mode and dimensions
+func (a *api) validateBattleTemplateSettings(t *battleTemplateRequestBody, UserID string) error {
+	if t.EstimationScaleID != "" {
+		if err := a.db.ConfirmEstimationScaleAccess(t.EstimationScaleID, UserID); err != nil {
+			return Errorf(EINVALID, err.Error())
+		}
+	}
+
+	if t.VotingMode == "" {
+		t.VotingMode = "live"
+	} else if t.VotingMode != "live" && t.VotingMode != "async" {
+		return Errorf(EINVALID, "INVALID_VOTING_MODE")
+	}
+
+	if t.Dimensions == nil {
+		t.Dimensions = make([]*model.BattleDimension, 0)
+	}
+	for _, dim := range t.Dimensions {
+		if dim.EstimationScaleID != "" {
+			if err := a.db.ConfirmEstimationScaleAccess(dim.EstimationScaleID, UserID); err != nil {
+				return Errorf(EINVALID, err.Error())
+			}
+		}
+	}
+	if err := a.db.PrepareBattleDimensions(t.Dimensions, t.DimensionFormula); err != nil {
+		return Errorf(EINVALID, err.Error())
+	}
+
+	return nil
+}
+
+// validateBattleTemplateLeaders confirms the templates leaders are members of the team, or registered users for personal templates
+func (a *api) validateBattleTemplateLeaders(Leaders []string, TeamID string) error {
+	for _, LeaderID := range Leaders {
+		var err error
+		if TeamID != "" {
+			_, err = a.db.TeamUserRole(LeaderID, TeamID)
+		} else {
+			_, err = a.db.GetUser(LeaderID)
+		}
+		if err != nil {
+			return Errorf(EINVALID, "INVALID_TEMPLATE_LEADER")
+		}
+	}
+
+	return nil
+}
+
+// handleBattleTemplatesGet gets a list of battle templates for the user or team
+// @Summary Get Battle Templates
+// @Description Get a list of battle templates for the user or team
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Success 200 object standardJsonResponse{data=[]model.BattleTemplate}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/battle-templates [get]
+// @Router /teams/{teamId}/battle-templates [get]
+// @Router /{orgId}/teams/{teamId}/battle-templates [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/battle-templates [get]
+func (a *api) handleBattleTemplatesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var Templates []*model.BattleTemplate
+		var err error
+		if TeamID, ok := vars["teamId"]; ok {
+			Templates, err = a.db.BattleTemplateListByTeam(TeamID)
+		} else {
+			Templates, err = a.db.BattleTemplateListByUser(vars["userId"])
+		}
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Templates, nil)
+	}
+}
+
+// handleBattleTemplateCreate handles creating a battle template for the user or team,
+// when a battleId is provided the template is saved from that battles settings and leaders
+// @Summary Create Battle Template
+// @Description Creates a battle template for the user or team from the provided settings or an existing battle
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param template body battleTemplateRequestBody true "new battle template object"
+// @Success 200 object standardJsonResponse{data=model.BattleTemplate}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/battle-templates [post]
+// @Router /teams/{teamId}/battle-templates [post]
+// @Router /{orgId}/teams/{teamId}/battle-templates [post]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/battle-templates [post]
+func (a *api) handleBattleTemplateCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		SessionUserID := r.Context().Value(contextKeyUserID).(string)
+		UserType := r.Context().Value(contextKeyUserType).(string)
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var t = battleTemplateRequestBody{}
+		if jsonErr := json.Unmarshal(body, &t); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		v := validator.New()
+		if err := v.Struct(t); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		if t.BattleID != "" {
+			if err := a.db.ConfirmLeader(t.BattleID, SessionUserID); err != nil && UserType != adminUserType {
+				a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, "REQUIRES_BATTLE_LEADER"))
+				return
+			}
+			Battle, err := a.db.GetBattle(t.BattleID, SessionUserID)
+			if err != nil {
+				a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, "BATTLE_NOT_FOUND"))
+				return
+			}
+			t.BattleName = Battle.Name
+			t.PointValuesAllowed = Battle.PointValuesAllowed
+			t.EstimationScaleID = Battle.EstimationScaleID
+			t.AutoFinishVoting = Battle.AutoFinishVoting
+			t.PointAverageRounding = Battle.PointAverageRounding
+			t.VotingTimerSeconds = Battle.VotingTimerSeconds
+			t.AnonymousVoting = Battle.AnonymousVoting
+			t.VotingMode = Battle.VotingMode
+			t.Dimensions = Battle.Dimensions
+			t.DimensionFormula = Battle.DimensionFormula
+			t.Leaders = Battle.Leaders
+		} else {
+			if err := a.validateBattleTemplateSettings(&t, SessionUserID); err != nil {
+				a.Failure(w, r, http.StatusBadRequest, err)
+				return
+			}
+			if err := a.validateBattleTemplateLeaders(t.Leaders, vars["teamId"]); err != nil {
+				a.Failure(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		if t.PointAverageRounding == "" {
+			t.PointAverageRounding = "ceil"
+		}
+
+		var UserID string
+		TeamID, ok := vars["teamId"]
+		if !ok {
+			UserID = vars["userId"]
+		}
+
+		Template, err := a.db.BattleTemplateCreate(
+			UserID, TeamID, t.Name,
+			t.BattleName, t.PointValuesAllowed, t.EstimationScaleID,
+			t.AutoFinishVoting, t.PointAverageRounding, t.VotingTimerSeconds, t.AnonymousVoting,
+			t.VotingMode, t.Dimensions, t.DimensionFormula, t.Leaders,
+		)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Template, nil)
+	}
+}
+
+// handleBattleTemplateDelete handles deleting a battle template
+// @Summary Delete Battle Template
+// @Description Deletes a battle template
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param templateId path string true "the battle template ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/battle-templates/{templateId} [delete]
+// @Router /teams/{teamId}/battle-templates/{templateId} [delete]
+// @Router /{orgId}/teams/{teamId}/battle-templates/{templateId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/battle-templates/{templateId} [delete]
+func (a *api) handleBattleTemplateDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		Template, err := a.getBattleTemplateForEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		if err := a.db.BattleTemplateDelete(Template.Id); err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, nil, nil)
+	}
+}
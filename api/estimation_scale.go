@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type estimationScaleRequestBody struct {
+	Name        string                        `json:"name" validate:"required,max=256"`
+	Description string                        `json:"description"`
+	IsDefault   bool                          `json:"isDefault"`
+	Values      []*model.EstimationScaleValue `json:"values" validate:"required,min=1,dive,required"`
+}
+
+// getEstimationScaleRequestBody reads and validates the estimation scale request body
+func getEstimationScaleRequestBody(r *http.Request) (*estimationScaleRequestBody, error) {
+	body, bodyErr := ioutil.ReadAll(r.Body)
+	if bodyErr != nil {
+		return nil, Errorf(EINVALID, bodyErr.Error())
+	}
+
+	var s = estimationScaleRequestBody{}
+	if jsonErr := json.Unmarshal(body, &s); jsonErr != nil {
+		return nil, Errorf(EINVALID, jsonErr.Error())
+	}
+
+	v := validator.New()
+	if err := v.Struct(s); err != nil {
+		return nil, Errorf(EINVALID, err.Error())
+	}
+
+	seen := make(map[string]bool)
+	for _, sv := range s.Values {
+		if sv.Value == "" || len(sv.Value) > 32 || seen[sv.Value] {
+			return nil, Errorf(EINVALID, "INVALID_ESTIMATION_SCALE_VALUES")
+		}
+		seen[sv.Value] = true
+	}
+
+	return &s, nil
+}
+
+// getEstimationScaleForEntity gets the estimation scale by ID confirming it belongs to the team or user in the route
+func (a *api) getEstimationScaleForEntity(r *http.Request) (*model.EstimationScale, error) {
+	vars := mux.Vars(r)
+
+	Scale, err := a.db.EstimationScaleGet(vars["scaleId"])
+	if err != nil {
+		return nil, Errorf(ENOTFOUND, err.Error())
+	}
+
+	if TeamID, ok := vars["teamId"]; ok {
+		if Scale.TeamId != TeamID {
+			return nil, Errorf(ENOTFOUND, "ESTIMATION_SCALE_NOT_FOUND")
+		}
+	} else if Scale.TeamId != "" || Scale.UserId != vars["userId"] {
+		return nil, Errorf(ENOTFOUND, "ESTIMATION_SCALE_NOT_FOUND")
+	}
+
+	return Scale, nil
+}
+
+// defaultEstimationScaleID gets the default estimation scale ID for a new battle,
+// preferring the teams default scale over the users when created for a team
+func (a *api) defaultEstimationScaleID(UserID string, TeamID string) string {
+	if TeamID != "" {
+		Scales, err := a.db.EstimationScaleListByTeam(TeamID)
+		if err == nil {
+			for _, s := range Scales {
+				if s.IsDefault {
+					return s.Id
+				}
+			}
+		}
+	}
+
+	Scale, err := a.db.EstimationScaleGetUserDefault(UserID)
+	if err != nil {
+		return ""
+	}
+
+	return Scale.Id
+}
+
+// handleEstimationScalesGet gets a list of estimation scales for the user or team
+// @Summary Get Estimation Scales
+// @Description Get a list of estimation scales for the user or team
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Success 200 object standardJsonResponse{data=[]model.EstimationScale}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/estimation-scales [get]
+// @Router /teams/{teamId}/estimation-scales [get]
+// @Router /{orgId}/teams/{teamId}/estimation-scales [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales [get]
+func (a *api) handleEstimationScalesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var Scales []*model.EstimationScale
+		var err error
+		if TeamID, ok := vars["teamId"]; ok {
+			Scales, err = a.db.EstimationScaleListByTeam(TeamID)
+		} else {
+			Scales, err = a.db.EstimationScaleListByUser(vars["userId"])
+		}
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Scales, nil)
+	}
+}
+
+// handleEstimationScaleCreate handles creating an estimation scale for the user or team
+// @Summary Create Estimation Scale
+// @Description Creates an estimation scale for the user or team
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param scale body estimationScaleRequestBody true "new estimation scale object"
+// @Success 200 object standardJsonResponse{data=model.EstimationScale}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/estimation-scales [post]
+// @Router /teams/{teamId}/estimation-scales [post]
+// @Router /{orgId}/teams/{teamId}/estimation-scales [post]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales [post]
+func (a *api) handleEstimationScaleCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		s, err := getEstimationScaleRequestBody(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var UserID string
+		TeamID, ok := vars["teamId"]
+		if !ok {
+			UserID = vars["userId"]
+		}
+
+		Scale, err := a.db.EstimationScaleCreate(UserID, TeamID, s.Name, s.Description, s.IsDefault, s.Values)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Scale, nil)
+	}
+}
+
+// handleEstimationScaleUpdate handles updating an estimation scale
+// @Summary Update Estimation Scale
+// @Description Updates an estimation scale replacing its values
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param scaleId path string true "the estimation scale ID"
+// @Param scale body estimationScaleRequestBody true "updated estimation scale object"
+// @Success 200 object standardJsonResponse{data=model.EstimationScale}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/estimation-scales/{scaleId} [put]
+// @Router /teams/{teamId}/estimation-scales/{scaleId} [put]
+// @Router /{orgId}/teams/{teamId}/estimation-scales/{scaleId} [put]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales/{scaleId} [put]
+func (a *api) handleEstimationScaleUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		Scale, err := a.getEstimationScaleForEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		s, err := getEstimationScaleRequestBody(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		UpdatedScale, err := a.db.EstimationScaleUpdate(Scale.Id, s.Name, s.Description, s.IsDefault, s.Values)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, UpdatedScale, nil)
+	}
+}
+
+// handleEstimationScaleDelete handles deleting an estimation scale
+// @Summary Delete Estimation Scale
+// @Description Deletes an estimation scale, battles using it keep their point values
+// @Tags battle
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param scaleId path string true "the estimation scale ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/estimation-scales/{scaleId} [delete]
+// @Router /teams/{teamId}/estimation-scales/{scaleId} [delete]
+// @Router /{orgId}/teams/{teamId}/estimation-scales/{scaleId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/estimation-scales/{scaleId} [delete]
+func (a *api) handleEstimationScaleDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		Scale, err := a.getEstimationScaleForEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		if err := a.db.EstimationScaleDelete(Scale.Id); err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type observerTokenRequestBody struct {
+	Name string `json:"name" validate:"required,max=256"`
+}
+
+// observerTokenEntity gets the battle, retro or storyboard from the route confirming the user leads it
+func (a *api) observerTokenEntity(r *http.Request) (string, string, error) {
+	vars := mux.Vars(r)
+	UserID := r.Context().Value(contextKeyUserID).(string)
+	UserType := r.Context().Value(contextKeyUserType).(string)
+
+	if BattleID, ok := vars["battleId"]; ok {
+		if err := a.db.ConfirmLeader(BattleID, UserID); err != nil && UserType != adminUserType {
+			return "", "", Errorf(EUNAUTHORIZED, "REQUIRES_BATTLE_LEADER")
+		}
+		return "battle", BattleID, nil
+	}
+	if RetroID, ok := vars["retroId"]; ok {
+		if err := a.db.RetroConfirmOwner(RetroID, UserID); err != nil && UserType != adminUserType {
+			return "", "", Errorf(EUNAUTHORIZED, "REQUIRES_RETRO_OWNER")
+		}
+		return "retro", RetroID, nil
+	}
+	if err := a.db.ConfirmStoryboardOwner(vars["storyboardId"], UserID); err != nil && UserType != adminUserType {
+		return "", "", Errorf(EUNAUTHORIZED, "REQUIRES_STORYBOARD_OWNER")
+	}
+
+	return "storyboard", vars["storyboardId"], nil
+}
+
+// handleObserverTokensGet gets a list of the observer tokens for the battle, retro or storyboard
+// @Summary Get Observer Tokens
+// @Description Get a list of the read-only observer tokens for the battle, retro or storyboard
+// @Tags battle, retro, storyboard
+// @Produce  json
+// @Param battleId path string false "the battle ID"
+// @Param retroId path string false "the retro ID"
+// @Param storyboardId path string false "the storyboard ID"
+// @Success 200 object standardJsonResponse{data=[]model.ObserverToken}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/observer-tokens [get]
+// @Router /retros/{retroId}/observer-tokens [get]
+// @Router /storyboards/{storyboardId}/observer-tokens [get]
+func (a *api) handleObserverTokensGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		EntityType, EntityID, err := a.observerTokenEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusForbidden, err)
+			return
+		}
+
+		Tokens, err := a.db.ObserverTokenList(EntityType, EntityID)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Tokens, nil)
+	}
+}
+
+// handleObserverTokenCreate handles creating an observer token for the battle, retro or storyboard,
+// the token is only returned in full on creation and is passed as the observerToken websocket query param
+// @Summary Create Observer Token
+// @Description Creates a read-only observer token for the battle, retro or storyboard
+// @Tags battle, retro, storyboard
+// @Produce  json
+// @Param battleId path string false "the battle ID"
+// @Param retroId path string false "the retro ID"
+// @Param storyboardId path string false "the storyboard ID"
+// @Param token body observerTokenRequestBody true "new observer token object"
+// @Success 200 object standardJsonResponse{data=model.ObserverToken}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/observer-tokens [post]
+// @Router /retros/{retroId}/observer-tokens [post]
+// @Router /storyboards/{storyboardId}/observer-tokens [post]
+func (a *api) handleObserverTokenCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		UserID := r.Context().Value(contextKeyUserID).(string)
+
+		EntityType, EntityID, err := a.observerTokenEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusForbidden, err)
+			return
+		}
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var t = observerTokenRequestBody{}
+		if jsonErr := json.Unmarshal(body, &t); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		v := validator.New()
+		if err := v.Struct(t); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		Token, err := a.db.ObserverTokenCreate(EntityType, EntityID, UserID, t.Name)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Token, nil)
+	}
+}
+
+// handleObserverTokenDelete handles revoking an observer token, observers connected with it are dropped
+// @Summary Delete Observer Token
+// @Description Revokes a read-only observer token for the battle, retro or storyboard
+// @Tags battle, retro, storyboard
+// @Produce  json
+// @Param battleId path string false "the battle ID"
+// @Param retroId path string false "the retro ID"
+// @Param storyboardId path string false "the storyboard ID"
+// @Param tokenId path string true "the observer token ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/observer-tokens/{tokenId} [delete]
+// @Router /retros/{retroId}/observer-tokens/{tokenId} [delete]
+// @Router /storyboards/{storyboardId}/observer-tokens/{tokenId} [delete]
+func (a *api) handleObserverTokenDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		EntityType, EntityID, err := a.observerTokenEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusForbidden, err)
+			return
+		}
+
+		if err := a.db.ObserverTokenDelete(EntityType, EntityID, vars["tokenId"]); err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/api/battle"
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type planActualRequestBody struct {
+	ActualPoints string   `json:"actualPoints" validate:"max=16"`
+	ActualEffort *float64 `json:"actualEffort" validate:"omitempty,min=0"`
+}
+
+type teamPlanActualsResult struct {
+	Updated int `json:"updated"`
+}
+
+// handleBattlePlanActual handles recording the actual points and effort of a finalized battle plan
+// @Summary Set Battle Plan Actual
+// @Description Records the actual points and effort of a finalized battle plan
+// @Param battleId path string true "the battle ID"
+// @Param planId path string true "the plan ID"
+// @Param actual body planActualRequestBody true "plan actual object"
+// @Tags battle
+// @Produce  json
+// @Success 200 object standardJsonResponse{data=[]model.Plan}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/{planId}/actual [put]
+func (a *api) handleBattlePlanActual(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		BattleID := vars["battleId"]
+		UserID := r.Context().Value(contextKeyUserID).(string)
+		UserType := r.Context().Value(contextKeyUserType).(string)
+
+		if err := a.db.ConfirmLeader(BattleID, UserID); err != nil && UserType != adminUserType {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, "REQUIRES_BATTLE_LEADER"))
+			return
+		}
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var pa = planActualRequestBody{}
+		if jsonErr := json.Unmarshal(body, &pa); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		v := validator.New()
+		if err := v.Struct(pa); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		Plans, err := a.db.SetPlanActual(BattleID, vars["planId"], pa.ActualPoints, pa.ActualEffort)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		updatedPlans, _ := json.Marshal(Plans)
+		b.BroadcastEvent(BattleID, "plan_revised", string(updatedPlans))
+
+		a.Success(w, r, http.StatusOK, Plans, nil)
+	}
+}
+
+// handleTeamPlanActuals handles recording the actuals of the teams finalized plans in bulk by reference ID
+// @Summary Set Team Plan Actuals
+// @Description Records the actual points and effort of the finalized plans in the teams battles matching each reference ID
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param actuals body []model.PlanActual true "plan actuals by reference ID"
+// @Success 200 object standardJsonResponse{data=teamPlanActualsResult}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/plan-actuals [put]
+// @Router /{orgId}/teams/{teamId}/plan-actuals [put]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/plan-actuals [put]
+func (a *api) handleTeamPlanActuals() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var Actuals = make([]*model.PlanActual, 0)
+		if jsonErr := json.Unmarshal(body, &Actuals); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		for _, pa := range Actuals {
+			if len(pa.ActualPoints) > 16 || (pa.ActualEffort != nil && *pa.ActualEffort < 0) {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_PLAN_ACTUAL"))
+				return
+			}
+		}
+
+		Updated, err := a.db.TeamSetPlanActuals(vars["teamId"], Actuals)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, &teamPlanActualsResult{Updated: Updated}, nil)
+	}
+}
+
+// handleTeamEstimationAccuracy gets the teams estimation accuracy report
+// @Summary Get Team Estimation Accuracy
+// @Description Compares the estimates with the actuals of the plans across all the teams battles per point value, per user and per month
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Success 200 object standardJsonResponse{data=model.EstimationAccuracy}
+// @Failure 403 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/estimation-accuracy [get]
+// @Router /{orgId}/teams/{teamId}/estimation-accuracy [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/estimation-accuracy [get]
+func (a *api) handleTeamEstimationAccuracy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		Accuracy := a.db.TeamEstimationAccuracy(vars["teamId"])
+
+		a.Success(w, r, http.StatusOK, Accuracy, nil)
+	}
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/api/battle"
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/gorilla/mux"
+)
+
+const defaultImportPlanType = "Story"
+
+// planImportMaxBytes the largest import file accepted
+const planImportMaxBytes = 5 << 20
+
+// planImportMaxRows the most rows (or items) a single import can have
+const planImportMaxRows = 500
+
+// planImportRow a plan parsed from an import file along with its row (or item) number
+type planImportRow struct {
+	Row  int
+	Plan *model.Plan
+	Err  error
+}
+
+// planImportResult the outcome of importing a single row
+type planImportResult struct {
+	Row         int    `json:"row"`
+	Name        string `json:"name"`
+	ReferenceID string `json:"referenceId"`
+	Status      string `json:"status" enums:"created,skipped,failed"`
+	Error       string `json:"error,omitempty"`
+}
+
+// planImportReport the per row report of a plan import
+type planImportReport struct {
+	Created int                 `json:"created"`
+	Skipped int                 `json:"skipped"`
+	Failed  int                 `json:"failed"`
+	Rows    []*planImportResult `json:"rows"`
+}
+
+// jiraImportXML the parts of a Jira RSS/XML issue export used for plans
+type jiraImportXML struct {
+	Items []struct {
+		Summary      string `xml:"summary"`
+		Type         string `xml:"type"`
+		Key          string `xml:"key"`
+		Link         string `xml:"link"`
+		Description  string `xml:"description"`
+		CustomFields []struct {
+			Name   string   `xml:"customfieldname"`
+			Values []string `xml:"customfieldvalues>customfieldvalue"`
+		} `xml:"customfields>customfield"`
+	} `xml:"channel>item"`
+}
+
+// parsePlanImportCSV parses plans from CSV with columns name, type, reference id, link, description, acceptance criteria,
+// a header row is skipped when its first column is "name"
+func parsePlanImportCSV(r io.Reader) ([]*planImportRow, error) {
+	var rows = make([]*planImportRow, 0)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return nil, err
+			}
+			rows = append(rows, &planImportRow{Row: row, Plan: &model.Plan{}, Err: err})
+			continue
+		}
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "name") {
+			continue
+		}
+
+		for len(record) < 6 {
+			record = append(record, "")
+		}
+		rows = append(rows, &planImportRow{
+			Row: row,
+			Plan: &model.Plan{
+				Name:               strings.TrimSpace(record[0]),
+				Type:               strings.TrimSpace(record[1]),
+				ReferenceId:        strings.TrimSpace(record[2]),
+				Link:               strings.TrimSpace(record[3]),
+				Description:        record[4],
+				AcceptanceCriteria: record[5],
+			},
+		})
+	}
+
+	return rows, nil
+}
+
+// parsePlanImportJiraXML parses plans from a Jira RSS/XML issue export
+func parsePlanImportJiraXML(r io.Reader) ([]*planImportRow, error) {
+	var rows = make([]*planImportRow, 0)
+	var doc jiraImportXML
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	for i, item := range doc.Items {
+		var AcceptanceCriteria string
+		for _, cf := range item.CustomFields {
+			if strings.EqualFold(strings.TrimSpace(cf.Name), "acceptance criteria") {
+				AcceptanceCriteria = strings.TrimSpace(strings.Join(cf.Values, "\n"))
+			}
+		}
+
+		rows = append(rows, &planImportRow{
+			Row: i + 1,
+			Plan: &model.Plan{
+				Name:               strings.TrimSpace(item.Summary),
+				Type:               strings.TrimSpace(item.Type),
+				ReferenceId:        strings.TrimSpace(item.Key),
+				Link:               strings.TrimSpace(item.Link),
+				Description:        strings.TrimSpace(item.Description),
+				AcceptanceCriteria: AcceptanceCriteria,
+			},
+		})
+	}
+
+	return rows, nil
+}
+
+// validateImportPlan makes sure the imported plan fits the plan fields
+func validateImportPlan(plan *model.Plan) error {
+	switch {
+	case plan.Name == "":
+		return errors.New("PLAN_NAME_REQUIRED")
+	case len(plan.Name) > 256:
+		return errors.New("PLAN_NAME_TOO_LONG")
+	case len(plan.Type) > 64:
+		return errors.New("PLAN_TYPE_TOO_LONG")
+	case len(plan.ReferenceId) > 128:
+		return errors.New("PLAN_REFERENCE_ID_TOO_LONG")
+	}
+
+	return nil
+}
+
+// handleBattlePlansImport handles importing plans into a battle from CSV or Jira XML
+// @Summary Import Battle Plans
+// @Description Imports plans into a battle from CSV (name, type, reference id, link, description, acceptance criteria)
+// @Description or a Jira RSS/XML export, plans with a reference ID already in the battle are skipped,
+// @Description imports are limited to 5MB and 500 rows
+// @Param battleId path string true "the battle ID"
+// @Param format query string false "the import format, detected from the Content-Type when not provided" Enums(csv, jira)
+// @Tags battle
+// @Accept  plain
+// @Produce  json
+// @Success 200 object standardJsonResponse{data=planImportReport}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /battles/{battleId}/plans/import [post]
+func (a *api) handleBattlePlansImport(b *battle.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		BattleID := vars["battleId"]
+		UserID := r.Context().Value(contextKeyUserID).(string)
+
+		if err := a.db.ConfirmLeader(BattleID, UserID); err != nil {
+			a.Failure(w, r, http.StatusForbidden, Errorf(EUNAUTHORIZED, "REQUIRES_BATTLE_LEADER"))
+			return
+		}
+
+		Format := r.URL.Query().Get("format")
+		if Format == "" {
+			if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+				Format = "jira"
+			} else {
+				Format = "csv"
+			}
+		}
+
+		var rows []*planImportRow
+		var err error
+		Body := http.MaxBytesReader(w, r.Body, planImportMaxBytes)
+		switch Format {
+		case "csv":
+			rows, err = parsePlanImportCSV(Body)
+		case "jira":
+			if !a.config.AllowJiraImport {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "JIRA_IMPORT_DISABLED"))
+				return
+			}
+			rows, err = parsePlanImportJiraXML(Body)
+		default:
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_IMPORT_FORMAT"))
+			return
+		}
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+		if len(rows) > planImportMaxRows {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "TOO_MANY_IMPORT_ROWS"))
+			return
+		}
+
+		existingRefs := make(map[string]bool)
+		for _, p := range a.db.GetPlans(BattleID, UserID) {
+			if p.ReferenceId != "" {
+				existingRefs[p.ReferenceId] = true
+			}
+		}
+
+		report := &planImportReport{
+			Rows: make([]*planImportResult, 0, len(rows)),
+		}
+		var Plans []*model.Plan
+		var created []*planImportResult
+		for _, row := range rows {
+			result := &planImportResult{
+				Row:         row.Row,
+				Name:        row.Plan.Name,
+				ReferenceID: row.Plan.ReferenceId,
+			}
+			report.Rows = append(report.Rows, result)
+
+			if row.Err == nil {
+				row.Err = validateImportPlan(row.Plan)
+			}
+			if row.Err != nil {
+				result.Status = "failed"
+				result.Error = row.Err.Error()
+				continue
+			}
+			if row.Plan.ReferenceId != "" && existingRefs[row.Plan.ReferenceId] {
+				result.Status = "skipped"
+				result.Error = "DUPLICATE_REFERENCE_ID"
+				continue
+			}
+			if row.Plan.ReferenceId != "" {
+				existingRefs[row.Plan.ReferenceId] = true
+			}
+			if row.Plan.Type == "" {
+				row.Plan.Type = defaultImportPlanType
+			}
+
+			result.Status = "created"
+			Plans = append(Plans, row.Plan)
+			created = append(created, result)
+		}
+
+		if len(Plans) > 0 {
+			plans, err := a.db.CreatePlans(BattleID, Plans)
+			if err != nil {
+				for _, result := range created {
+					result.Status = "failed"
+					result.Error = err.Error()
+				}
+			} else {
+				updatedPlans, _ := json.Marshal(plans)
+				b.BroadcastEvent(BattleID, "plan_added", string(updatedPlans))
+			}
+		}
+
+		for _, result := range report.Rows {
+			switch result.Status {
+			case "created":
+				report.Created++
+			case "skipped":
+				report.Skipped++
+			default:
+				report.Failed++
+			}
+		}
+
+		a.Success(w, r, http.StatusOK, report, nil)
+	}
+}
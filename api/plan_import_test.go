@@ -0,0 +1,76 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParsePlanImportCSV calls parsePlanImportCSV with a header row and makes sure
+// the header is skipped, missing columns are empty and quoted fields are kept whole
+func TestParsePlanImportCSV(t *testing.T) {
+	input := `name,type,reference id,link,description,acceptance criteria
+Login page,Story,TD-1,https://example.com/TD-1,"Users can log in, and out",Has a form
+Fix header,Bug,TD-2
+`
+	rows, err := parsePlanImportCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf(`parsePlanImportCSV = %v error`, err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf(`expected 2 rows got %d`, len(rows))
+	}
+
+	if rows[0].Row != 2 || rows[0].Plan.Name != "Login page" || rows[0].Plan.Description != "Users can log in, and out" {
+		t.Fatalf(`unexpected first row %+v`, rows[0].Plan)
+	}
+
+	if rows[1].Plan.ReferenceId != "TD-2" || rows[1].Plan.Link != "" {
+		t.Fatalf(`unexpected second row %+v`, rows[1].Plan)
+	}
+}
+
+// TestParsePlanImportJiraXML calls parsePlanImportJiraXML and makes sure the item fields
+// and the acceptance criteria custom field are used for the plan
+func TestParsePlanImportJiraXML(t *testing.T) {
+	input := `<rss version="0.92"><channel>
+<item>
+	<link>https://jira.example.com/browse/TD-3</link>
+	<key id="10003">TD-3</key>
+	<summary>Export battle results</summary>
+	<type id="10001">Story</type>
+	<description>&lt;p&gt;Allow exporting&lt;/p&gt;</description>
+	<customfields>
+		<customfield id="customfield_10100">
+			<customfieldname>Acceptance Criteria</customfieldname>
+			<customfieldvalues>
+				<customfieldvalue>&lt;p&gt;CSV download&lt;/p&gt;</customfieldvalue>
+				<customfieldvalue>&lt;p&gt;JSON download&lt;/p&gt;</customfieldvalue>
+			</customfieldvalues>
+		</customfield>
+	</customfields>
+</item>
+</channel></rss>`
+
+	rows, err := parsePlanImportJiraXML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf(`parsePlanImportJiraXML = %v error`, err)
+	}
+
+	if len(rows) != 1 {
+		t.Fatalf(`expected 1 row got %d`, len(rows))
+	}
+
+	plan := rows[0].Plan
+	if plan.Name != "Export battle results" || plan.ReferenceId != "TD-3" || plan.Type != "Story" {
+		t.Fatalf(`unexpected plan %+v`, plan)
+	}
+
+	if plan.Description != "<p>Allow exporting</p>" {
+		t.Fatalf(`expected decoded description got %s`, plan.Description)
+	}
+
+	if plan.AcceptanceCriteria != "<p>CSV download</p>\n<p>JSON download</p>" {
+		t.Fatalf(`expected acceptance criteria got %s`, plan.AcceptanceCriteria)
+	}
+}
@@ -9,17 +9,23 @@ import (
 
 	"github.com/StevenWeathers/thunderdome-planning-poker/model"
 	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
 )
 
 type retroCreateRequestBody struct {
-	RetroName string `json:"retroName" example:"sprint 10 retro"`
-	Format    string `json:"format" example:"worked_improve_question"`
-	JoinCode  string `json:"joinCode" example:"iammadmax"`
+	RetroName             string `json:"retroName" example:"sprint 10 retro"`
+	Format                string `json:"format" example:"worked_improve_question"`
+	TemplateID            string `json:"templateId"`
+	JoinCode              string `json:"joinCode" example:"iammadmax"`
+	MaxVotes              int    `json:"maxVotes" validate:"min=0,max=50" example:"3"`
+	AllowCumulativeVoting bool   `json:"allowCumulativeVoting"`
+	HideVotesDuringVoting bool   `json:"hideVotesDuringVoting"`
+	CarryOverActions      bool   `json:"carryOverActions"`
 }
 
 // handleRetroCreate handles creating a retro
 // @Summary Create Retro
-// @Description Create a retro associated to the user
+// @Description Create a retro associated to the user, using the columns of either a built-in format or the selected retro template, team retros can carry over the incomplete actions of the teams previous retros to review before brainstorming, carrying over actions requires a team
 // @Tags retro
 // @Produce  json
 // @Param userId path string true "the user ID"
@@ -28,6 +34,7 @@ type retroCreateRequestBody struct {
 // @Param teamId path string false "the team ID"
 // @Param retro body retroCreateRequestBody false "new retro object"
 // @Success 200 object standardJsonResponse{data=model.Retro}
+// @Failure 400 object standardJsonResponse{}
 // @Failure 403 object standardJsonResponse{}
 // @Failure 500 object standardJsonResponse{}
 // @Security ApiKeyAuth
@@ -53,8 +60,45 @@ func (a *api) handleRetroCreate() http.HandlerFunc {
 			return
 		}
 
-		newRetro, err := a.db.RetroCreate(userID, nr.RetroName, nr.Format, nr.JoinCode)
+		v := validator.New()
+		if err := v.Struct(nr); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+		if nr.MaxVotes == 0 {
+			nr.MaxVotes = 3
+		}
+		if _, ok := vars["teamId"]; !ok && nr.CarryOverActions {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "CARRY_OVER_REQUIRES_TEAM"))
+			return
+		}
+
+		var Columns []*model.RetroColumn
+		if nr.TemplateID != "" {
+			if err := a.db.ConfirmRetroTemplateAccess(nr.TemplateID, userID); err != nil {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
+			Template, err := a.db.RetroTemplateGet(nr.TemplateID)
+			if err != nil {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
+			nr.Format = "custom"
+			Columns = Template.Columns
+		} else if nr.Format == "" {
+			nr.Format = "worked_improve_question"
+		}
+
+		newRetro, err := a.db.RetroCreate(
+			userID, nr.RetroName, nr.Format, nr.JoinCode, Columns,
+			nr.MaxVotes, nr.AllowCumulativeVoting, nr.HideVotesDuringVoting,
+		)
 		if err != nil {
+			if err.Error() == "INVALID_RETRO_FORMAT" {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -80,6 +124,14 @@ func (a *api) handleRetroCreate() http.HandlerFunc {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
+
+				if nr.CarryOverActions {
+					if err := a.db.RetroCarryOverActions(TeamID, newRetro.Id); err != nil {
+						a.Failure(w, r, http.StatusInternalServerError, err)
+						return
+					}
+					newRetro.ReviewActions = a.db.GetRetroReviewActions(newRetro.Id)
+				}
 			}
 		}
 
@@ -110,6 +162,11 @@ func (a *api) handleRetroGet() http.HandlerFunc {
 			return
 		}
 
+		// while votes are hidden users only see their own
+		if re.HideVotesDuringVoting && re.Phase == "vote" {
+			re.Votes = a.db.FilterVotesByUser(r.Context().Value(contextKeyUserID).(string), re.Votes)
+		}
+
 		a.Success(w, r, http.StatusOK, re, nil)
 	}
 }
@@ -184,20 +241,24 @@ func (a *api) handleGetRetros() http.HandlerFunc {
 }
 
 type actionUpdateRequestBody struct {
-	ActionID  string `json:"id" swaggerignore:"true"`
-	Completed bool   `json:"completed" example:"false"`
-	Content   string `json:"content" example:"update documentation"`
+	ActionID  string  `json:"id" swaggerignore:"true"`
+	Completed bool    `json:"completed" example:"false"`
+	Content   string  `json:"content" example:"update documentation"`
+	Status    *string `json:"status,omitempty" enums:"open,in_progress,blocked,done,dropped" example:"in_progress"`
+	Priority  *string `json:"priority,omitempty" enums:"low,medium,high" example:"high"`
+	DueDate   *string `json:"dueDate,omitempty" example:"2022-08-01"`
 }
 
 // handleRetroActionUpdate handles updating a retro action item
 // @Summary Retro Action Item Update
-// @Description Update a retro action item
+// @Description Update a retro action items content, status, priority and due date
 // @Param retroId path string true "the retro ID"
 // @Param actionId path string true "the action ID"
 // @Param actionItem body actionUpdateRequestBody true "updated action item"
 // @Tags retro
 // @Produce  json
 // @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
 // @Success 403 object standardJsonResponse{}
 // @Success 500 object standardJsonResponse{}
 // @Security ApiKeyAuth
@@ -227,7 +288,7 @@ func (a *api) handleRetroActionUpdate(rs *retro.Service) http.HandlerFunc {
 
 		err := rs.APIEvent(RetroID, UserID, "update_action", string(updatedActionJson))
 		if err != nil {
-			a.Failure(w, r, http.StatusInternalServerError, err)
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
 			return
 		}
 
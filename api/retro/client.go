@@ -57,7 +57,7 @@ func (sub subscription) readPump(b *Service) {
 		UpdatedUsers, _ := json.Marshal(Users)
 
 		retreatEvent := createSocketEvent("user_left", string(UpdatedUsers), UserID)
-		m := message{retreatEvent, RetroID}
+		m := message{retreatEvent, RetroID, ""}
 		h.broadcast <- m
 
 		h.unregister <- sub
@@ -78,6 +78,7 @@ func (sub subscription) readPump(b *Service) {
 	for {
 		var badEvent bool
 		var eventErr error
+		var userOnly bool
 		_, msg, err := c.ws.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
@@ -111,10 +112,19 @@ func (sub subscription) readPump(b *Service) {
 					b.logger.Error("unexpected close error", zap.Error(eventErr))
 				}
 			}
+		} else if handler, ok := b.voteEventHandlers[eventType]; ok && !badEvent {
+			msg, userOnly, eventErr = handler(RetroID, UserID, eventValue)
+			if eventErr != nil {
+				badEvent = true
+				b.logger.Error("unexpected close error", zap.Error(eventErr))
+			}
 		}
 
 		if !badEvent {
-			m := message{msg, sub.arena}
+			m := message{msg, sub.arena, ""}
+			if userOnly {
+				m.user = UserID
+			}
 			h.broadcast <- m
 		}
 
@@ -183,6 +193,12 @@ func (b *Service) ServeWs() http.HandlerFunc {
 		}
 		c := &connection{send: make(chan []byte, 256), ws: ws}
 
+		// observers authenticate with a read-only token instead of a user session
+		if ObserverToken := r.URL.Query().Get("observerToken"); ObserverToken != "" {
+			b.serveObserver(c, retroID, ObserverToken)
+			return
+		}
+
 		SessionId, cookieErr := b.validateSessionCookie(w, r)
 		if cookieErr != nil && cookieErr.Error() != "NO_SESSION_COOKIE" {
 			b.handleSocketClose(ws, 4001, "unauthorized")
@@ -267,12 +283,13 @@ func (b *Service) ServeWs() http.HandlerFunc {
 				Users, _ := b.db.RetroAddUser(ss.arena, User.Id)
 				UpdatedUsers, _ := json.Marshal(Users)
 
+				b.hideVotes(retro, User.Id)
 				Retro, _ := json.Marshal(retro)
 				initEvent := createSocketEvent("init", string(Retro), User.Id)
 				_ = c.write(websocket.TextMessage, initEvent)
 
 				joinedEvent := createSocketEvent("user_joined", string(UpdatedUsers), User.Id)
-				m := message{joinedEvent, ss.arena}
+				m := message{joinedEvent, ss.arena, ""}
 				h.broadcast <- m
 
 				go ss.writePump()
@@ -302,7 +319,20 @@ func (b *Service) APIEvent(arenaID string, UserID, eventType string, eventValue
 		}
 
 		if _, ok := h.arenas[arenaID]; ok {
-			m := message{msg, arenaID}
+			m := message{msg, arenaID, ""}
+			h.broadcast <- m
+		}
+	} else if handler, ok := b.voteEventHandlers[eventType]; ok {
+		msg, userOnly, eventErr := handler(arenaID, UserID, eventValue)
+		if eventErr != nil {
+			return eventErr
+		}
+
+		if _, ok := h.arenas[arenaID]; ok {
+			m := message{msg, arenaID, ""}
+			if userOnly {
+				m.user = UserID
+			}
 			h.broadcast <- m
 		}
 	}
@@ -3,8 +3,15 @@ package retro
 import (
 	"encoding/json"
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
 )
 
+// maxVoteBudget the most votes a retro can give each user
+const maxVoteBudget = 50
+
 // CreateItem creates a retro item
 func (b *Service) CreateItem(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
 	var rs struct {
@@ -14,6 +21,14 @@ func (b *Service) CreateItem(RetroID string, UserID string, EventValue string) (
 	}
 	json.Unmarshal([]byte(EventValue), &rs)
 
+	columns, err := b.db.GetRetroColumns(RetroID)
+	if err != nil {
+		return nil, err, false
+	}
+	if !validItemType(columns, rs.Type) {
+		return nil, errors.New("INVALID_ITEM_TYPE"), false
+	}
+
 	items, err := b.db.CreateRetroItem(RetroID, UserID, rs.Type, rs.Content)
 	if err != nil {
 		return nil, err, false
@@ -83,48 +98,80 @@ func (b *Service) GroupNameChange(RetroID string, UserID string, EventValue stri
 	return msg, nil, false
 }
 
-// GroupUserVote handles a users vote for an item group
-func (b *Service) GroupUserVote(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+// GroupUserVote handles a users vote for an item group, the event is only for the user while votes are hidden
+func (b *Service) GroupUserVote(RetroID string, UserID string, EventValue string) ([]byte, bool, error) {
 	var rs struct {
 		GroupId string `json:"groupId"`
 	}
 	json.Unmarshal([]byte(EventValue), &rs)
 
+	settings, err := b.db.GetRetroVoteSettings(RetroID)
+	if err != nil {
+		return nil, false, err
+	}
+
 	vc, vcErr := b.db.RetroUserVoteCount(RetroID, UserID)
 	if vcErr != nil {
-		return nil, vcErr, false
+		return nil, false, vcErr
 	}
-	if vc == 3 {
-		return nil, errors.New("VOTE_LIMIT_REACHED"), false
+	if vc >= settings.MaxVotes {
+		return nil, false, errors.New("VOTE_LIMIT_REACHED")
 	}
 
-	votes, err := b.db.GroupUserVote(RetroID, rs.GroupId, UserID)
+	votes, err := b.db.GroupUserVote(RetroID, rs.GroupId, UserID, settings.AllowCumulativeVoting)
 	if err != nil {
-		return nil, err, false
+		return nil, false, err
 	}
 
-	updatedVotes, _ := json.Marshal(votes)
-	msg := createSocketEvent("votes_updated", string(updatedVotes), "")
+	msg, hidden := b.votesUpdatedEvent(settings, UserID, votes)
 
-	return msg, nil, false
+	return msg, hidden, nil
 }
 
-// GroupUserSubtractVote handles removing a users vote from an item group
-func (b *Service) GroupUserSubtractVote(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+// GroupUserSubtractVote handles removing a users vote from an item group, the event is only for the user while votes are hidden
+func (b *Service) GroupUserSubtractVote(RetroID string, UserID string, EventValue string) ([]byte, bool, error) {
 	var rs struct {
 		GroupId string `json:"groupId"`
 	}
 	json.Unmarshal([]byte(EventValue), &rs)
 
+	settings, err := b.db.GetRetroVoteSettings(RetroID)
+	if err != nil {
+		return nil, false, err
+	}
+
 	votes, err := b.db.GroupUserSubtractVote(RetroID, rs.GroupId, UserID)
 	if err != nil {
-		return nil, err, false
+		return nil, false, err
 	}
 
-	updatedVotes, _ := json.Marshal(votes)
-	msg := createSocketEvent("votes_updated", string(updatedVotes), "")
+	msg, hidden := b.votesUpdatedEvent(settings, UserID, votes)
 
-	return msg, nil, false
+	return msg, hidden, nil
+}
+
+// votesHidden checks whether the retros votes are hidden from other users in its current phase
+func votesHidden(Retro *model.Retro) bool {
+	return Retro.HideVotesDuringVoting && Retro.Phase == "vote"
+}
+
+// hideVotes limits the retros votes to the users own while votes are hidden
+func (b *Service) hideVotes(Retro *model.Retro, UserID string) {
+	if votesHidden(Retro) {
+		Retro.Votes = b.db.FilterVotesByUser(UserID, Retro.Votes)
+	}
+}
+
+// votesUpdatedEvent creates the votes_updated event, while votes are hidden it only has the voting users own votes
+// and is reported as hidden so it's only sent to the users connections
+func (b *Service) votesUpdatedEvent(Settings *model.Retro, UserID string, Votes []*model.RetroVote) ([]byte, bool) {
+	if votesHidden(Settings) {
+		updatedVotes, _ := json.Marshal(b.db.FilterVotesByUser(UserID, Votes))
+		return createSocketEvent("votes_updated", string(updatedVotes), UserID), true
+	}
+
+	updatedVotes, _ := json.Marshal(Votes)
+	return createSocketEvent("votes_updated", string(updatedVotes), ""), false
 }
 
 // CreateAction creates a retro action
@@ -145,16 +192,83 @@ func (b *Service) CreateAction(RetroID string, UserID string, EventValue string)
 	return msg, nil, false
 }
 
-// UpdateAction updates a retro action
+// actionStatuses the workflow statuses of a retro action
+var actionStatuses = map[string]struct{}{
+	"open":        {},
+	"in_progress": {},
+	"blocked":     {},
+	"done":        {},
+	"dropped":     {},
+}
+
+// actionPriorities the priorities of a retro action
+var actionPriorities = map[string]struct{}{
+	"low":    {},
+	"medium": {},
+	"high":   {},
+}
+
+// actionDueDateLayout the layout of a retro actions due date
+const actionDueDateLayout = "2006-01-02"
+
+// UpdateAction updates a retro action, the status, priority and due date are kept when not provided
+// and without a status a change to completed moves the action to done or back to open
 func (b *Service) UpdateAction(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
 	var rs struct {
-		ActionID  string `json:"id"`
-		Completed bool   `json:"completed"`
-		Content   string `json:"content"`
+		ActionID  string  `json:"id"`
+		Completed bool    `json:"completed"`
+		Content   string  `json:"content"`
+		Status    *string `json:"status"`
+		Priority  *string `json:"priority"`
+		DueDate   *string `json:"dueDate"`
 	}
 	json.Unmarshal([]byte(EventValue), &rs)
 
-	items, err := b.db.UpdateRetroAction(RetroID, rs.ActionID, rs.Content, rs.Completed)
+	var action *model.RetroAction
+	for _, a := range b.db.GetRetroActions(RetroID) {
+		if a.ID == rs.ActionID {
+			action = a
+			break
+		}
+	}
+	if action == nil {
+		return nil, errors.New("RETRO_ACTION_NOT_FOUND"), false
+	}
+
+	Status := action.Status
+	if rs.Status != nil {
+		Status = *rs.Status
+	} else if rs.Completed != action.Completed {
+		Status = "open"
+		if rs.Completed {
+			Status = "done"
+		}
+	}
+	if _, ok := actionStatuses[Status]; !ok {
+		return nil, errors.New("INVALID_ACTION_STATUS"), false
+	}
+
+	Priority := action.Priority
+	if rs.Priority != nil {
+		Priority = *rs.Priority
+	}
+	if _, ok := actionPriorities[Priority]; !ok {
+		return nil, errors.New("INVALID_ACTION_PRIORITY"), false
+	}
+
+	DueDate := action.DueDate
+	if rs.DueDate != nil {
+		DueDate = nil
+		if *rs.DueDate != "" {
+			due, err := time.Parse(actionDueDateLayout, *rs.DueDate)
+			if err != nil {
+				return nil, errors.New("INVALID_ACTION_DUE_DATE"), false
+			}
+			DueDate = &due
+		}
+	}
+
+	items, err := b.db.UpdateRetroAction(RetroID, rs.ActionID, rs.Content, Status, Priority, DueDate)
 	if err != nil {
 		return nil, err, false
 	}
@@ -183,6 +297,177 @@ func (b *Service) DeleteAction(RetroID string, UserID string, EventValue string)
 	return msg, nil, false
 }
 
+// ActionCommentAdd adds a comment to a retro action
+func (b *Service) ActionCommentAdd(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var rs struct {
+		ActionID string `json:"actionId"`
+		Comment  string `json:"comment"`
+	}
+	json.Unmarshal([]byte(EventValue), &rs)
+
+	if strings.TrimSpace(rs.Comment) == "" {
+		return nil, errors.New("INVALID_ACTION_COMMENT"), false
+	}
+	if err := b.db.ConfirmRetroAction(RetroID, rs.ActionID); err != nil {
+		return nil, err, false
+	}
+
+	items, err := b.db.RetroActionCommentAdd(RetroID, rs.ActionID, UserID, rs.Comment)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updatedItems, _ := json.Marshal(items)
+	msg := createSocketEvent("action_updated", string(updatedItems), "")
+
+	return msg, nil, false
+}
+
+// ActionCommentEdit edits a retro action comment, only the comments author can edit it
+func (b *Service) ActionCommentEdit(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var rs struct {
+		ActionID  string `json:"actionId"`
+		CommentID string `json:"commentId"`
+		Comment   string `json:"comment"`
+	}
+	json.Unmarshal([]byte(EventValue), &rs)
+
+	if strings.TrimSpace(rs.Comment) == "" {
+		return nil, errors.New("INVALID_ACTION_COMMENT"), false
+	}
+	if err := b.db.ConfirmRetroAction(RetroID, rs.ActionID); err != nil {
+		return nil, err, false
+	}
+	if err := b.db.ConfirmRetroActionCommentAuthor(rs.ActionID, rs.CommentID, UserID); err != nil {
+		return nil, err, false
+	}
+
+	items, err := b.db.RetroActionCommentEdit(RetroID, rs.ActionID, rs.CommentID, rs.Comment)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updatedItems, _ := json.Marshal(items)
+	msg := createSocketEvent("action_updated", string(updatedItems), "")
+
+	return msg, nil, false
+}
+
+// ActionCommentDelete deletes a retro action comment, either by its author or the retro owner
+func (b *Service) ActionCommentDelete(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var rs struct {
+		ActionID  string `json:"actionId"`
+		CommentID string `json:"commentId"`
+	}
+	json.Unmarshal([]byte(EventValue), &rs)
+
+	if err := b.db.ConfirmRetroAction(RetroID, rs.ActionID); err != nil {
+		return nil, err, false
+	}
+	if err := b.db.ConfirmRetroActionCommentAuthor(rs.ActionID, rs.CommentID, UserID); err != nil {
+		if ownerErr := b.db.RetroConfirmOwner(RetroID, UserID); ownerErr != nil {
+			return nil, err, false
+		}
+	}
+
+	items, err := b.db.RetroActionCommentDelete(RetroID, rs.ActionID, rs.CommentID)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updatedItems, _ := json.Marshal(items)
+	msg := createSocketEvent("action_updated", string(updatedItems), "")
+
+	return msg, nil, false
+}
+
+// ActionAssigneeAdd assigns a user to a retro action
+func (b *Service) ActionAssigneeAdd(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var rs struct {
+		ActionID string `json:"actionId"`
+		UserID   string `json:"userId"`
+	}
+	json.Unmarshal([]byte(EventValue), &rs)
+
+	if err := b.db.ConfirmRetroAction(RetroID, rs.ActionID); err != nil {
+		return nil, err, false
+	}
+	if err := b.db.ConfirmRetroActionAssignee(RetroID, rs.UserID); err != nil {
+		return nil, err, false
+	}
+
+	items, err := b.db.RetroActionAssigneeAdd(RetroID, rs.ActionID, rs.UserID)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updatedItems, _ := json.Marshal(items)
+	msg := createSocketEvent("action_updated", string(updatedItems), "")
+
+	return msg, nil, false
+}
+
+// ActionAssigneeDelete removes a users assignment from a retro action
+func (b *Service) ActionAssigneeDelete(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var rs struct {
+		ActionID string `json:"actionId"`
+		UserID   string `json:"userId"`
+	}
+	json.Unmarshal([]byte(EventValue), &rs)
+
+	if err := b.db.ConfirmRetroAction(RetroID, rs.ActionID); err != nil {
+		return nil, err, false
+	}
+
+	items, err := b.db.RetroActionAssigneeDelete(RetroID, rs.ActionID, rs.UserID)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updatedItems, _ := json.Marshal(items)
+	msg := createSocketEvent("action_updated", string(updatedItems), "")
+
+	return msg, nil, false
+}
+
+// reviewDecisions the decisions that can be made on an action carried over for review
+var reviewDecisions = map[string]struct{}{
+	"close": {},
+	"keep":  {},
+	"drop":  {},
+}
+
+// ReviewAction handles closing, keeping or dropping an action carried over from a previous team retro
+func (b *Service) ReviewAction(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
+	var rs struct {
+		ActionID string `json:"actionId"`
+		Decision string `json:"decision"`
+	}
+	json.Unmarshal([]byte(EventValue), &rs)
+
+	if _, ok := reviewDecisions[rs.Decision]; !ok {
+		return nil, errors.New("INVALID_REVIEW_DECISION"), false
+	}
+
+	Phase, err := b.db.GetRetroPhase(RetroID)
+	if err != nil {
+		return nil, err, false
+	}
+	if Phase != "review" {
+		return nil, errors.New("RETRO_NOT_IN_REVIEW"), false
+	}
+
+	actions, err := b.db.RetroReviewAction(RetroID, rs.ActionID, rs.Decision)
+	if err != nil {
+		return nil, err, false
+	}
+
+	updatedActions, _ := json.Marshal(actions)
+	msg := createSocketEvent("review_actions_updated", string(updatedActions), "")
+
+	return msg, nil, false
+}
+
 // AdvancePhase updates a retro phase
 func (b *Service) AdvancePhase(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
 	var rs struct {
@@ -190,10 +475,15 @@ func (b *Service) AdvancePhase(RetroID string, UserID string, EventValue string)
 	}
 	json.Unmarshal([]byte(EventValue), &rs)
 
+	if rs.Phase == "review" && len(b.db.GetRetroReviewActions(RetroID)) == 0 {
+		return nil, errors.New("NO_ACTIONS_TO_REVIEW"), false
+	}
+
 	retro, err := b.db.RetroAdvancePhase(RetroID, rs.Phase)
 	if err != nil {
 		return nil, err, false
 	}
+	b.hideVotes(retro, "")
 
 	updatedItems, _ := json.Marshal(retro)
 	msg := createSocketEvent("retro_updated", string(updatedItems), "")
@@ -204,19 +494,35 @@ func (b *Service) AdvancePhase(RetroID string, UserID string, EventValue string)
 // EditRetro handles editing the retro settings
 func (b *Service) EditRetro(RetroID string, UserID string, EventValue string) ([]byte, error, bool) {
 	var rb struct {
-		Name     string `json:"retroName"`
-		JoinCode string `json:"joinCode"`
+		Name                  string `json:"retroName"`
+		JoinCode              string `json:"joinCode"`
+		MaxVotes              int    `json:"maxVotes"`
+		AllowCumulativeVoting *bool  `json:"allowCumulativeVoting"`
+		HideVotesDuringVoting *bool  `json:"hideVotesDuringVoting"`
 	}
 	json.Unmarshal([]byte(EventValue), &rb)
 
+	if rb.MaxVotes < 0 || rb.MaxVotes > maxVoteBudget {
+		return nil, errors.New("INVALID_VOTE_BUDGET"), false
+	}
+
 	err := b.db.EditRetro(
 		RetroID,
 		rb.Name,
 		rb.JoinCode,
+		rb.MaxVotes,
+		rb.AllowCumulativeVoting,
+		rb.HideVotesDuringVoting,
 	)
 	if err != nil {
 		return nil, err, false
 	}
+	// vote settings that weren't provided are kept so the event has the stored values
+	if settings, err := b.db.GetRetroVoteSettings(RetroID); err == nil {
+		rb.MaxVotes = settings.MaxVotes
+		rb.AllowCumulativeVoting = &settings.AllowCumulativeVoting
+		rb.HideVotesDuringVoting = &settings.HideVotesDuringVoting
+	}
 
 	updatedRetro, _ := json.Marshal(rb)
 	msg := createSocketEvent("retro_edited", string(updatedRetro), "")
@@ -260,3 +566,14 @@ func createSocketEvent(Type string, Value string, User string) []byte {
 
 	return event
 }
+
+// validItemType checks the item type is the key of one of the retros columns
+func validItemType(Columns []*model.RetroColumn, ItemType string) bool {
+	for _, c := range Columns {
+		if c.Key == ItemType {
+			return true
+		}
+	}
+
+	return false
+}
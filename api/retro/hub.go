@@ -3,6 +3,8 @@ package retro
 type message struct {
 	data  []byte
 	arena string
+	// when set the message is only sent to the users connections
+	user string
 }
 
 type subscription struct {
@@ -14,8 +16,8 @@ type subscription struct {
 // hub maintains the set of active connections and broadcasts messages to the
 // connections.
 type hub struct {
-	// Registered connections.
-	arenas map[string]map[*connection]struct{}
+	// Registered connections with their user ID.
+	arenas map[string]map[*connection]string
 
 	// Inbound messages from the connections.
 	broadcast chan message
@@ -31,7 +33,7 @@ var h = hub{
 	broadcast:  make(chan message),
 	register:   make(chan subscription),
 	unregister: make(chan subscription),
-	arenas:     make(map[string]map[*connection]struct{}),
+	arenas:     make(map[string]map[*connection]string),
 }
 
 func (h *hub) run() {
@@ -40,10 +42,10 @@ func (h *hub) run() {
 		case a := <-h.register:
 			connections := h.arenas[a.arena]
 			if connections == nil {
-				connections = make(map[*connection]struct{})
+				connections = make(map[*connection]string)
 				h.arenas[a.arena] = connections
 			}
-			h.arenas[a.arena][a.conn] = struct{}{}
+			h.arenas[a.arena][a.conn] = a.UserID
 		case a := <-h.unregister:
 			connections := h.arenas[a.arena]
 			if connections != nil {
@@ -57,7 +59,10 @@ func (h *hub) run() {
 			}
 		case m := <-h.broadcast:
 			connections := h.arenas[m.arena]
-			for c := range connections {
+			for c, user := range connections {
+				if m.user != "" && m.user != user {
+					continue
+				}
 				select {
 				case c.send <- m.data:
 				default:
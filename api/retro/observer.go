@@ -0,0 +1,70 @@
+package retro
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// observerEntityType the observer token entity type for retros
+const observerEntityType = "retro"
+
+// serveObserver subscribes a read-only observer to the retro, observers receive every broadcast
+// but are never added as a retro user and any events they send are dropped
+func (b *Service) serveObserver(c *connection, RetroID string, ObserverToken string) {
+	if err := b.db.ConfirmObserverToken(observerEntityType, RetroID, ObserverToken); err != nil {
+		b.handleSocketClose(c.ws, 4001, "unauthorized")
+		return
+	}
+
+	retro, retroErr := b.db.RetroGet(RetroID)
+	if retroErr != nil {
+		b.handleSocketClose(c.ws, 4004, "retro not found")
+		return
+	}
+	retro.JoinCode = ""
+	b.hideVotes(retro, "")
+
+	ss := subscription{c, RetroID, ""}
+	h.register <- ss
+
+	Retro, _ := json.Marshal(retro)
+	initEvent := createSocketEvent("init", string(Retro), "")
+	_ = c.write(websocket.TextMessage, initEvent)
+
+	go ss.writePump()
+	go ss.observerReadPump(b, ObserverToken)
+}
+
+// observerReadPump keeps the observer connection open until it closes or its token is revoked
+func (sub subscription) observerReadPump(b *Service, ObserverToken string) {
+	c := sub.conn
+
+	defer func() {
+		h.unregister <- sub
+		if err := c.ws.Close(); err != nil {
+			b.logger.Error("close error", zap.Error(err))
+		}
+	}()
+	c.ws.SetReadLimit(maxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		if err := b.db.ConfirmObserverToken(observerEntityType, sub.arena, ObserverToken); err != nil {
+			return errors.New("OBSERVER_TOKEN_REVOKED")
+		}
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+				b.logger.Error("unexpected close error", zap.Error(err))
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package retro
+
+import (
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// actionReminderInterval how often retro actions are checked for a passed due date
+const actionReminderInterval = time.Hour
+
+// watchOverdueActions periodically emails assignees of retro actions that are past their due date
+func (rs *Service) watchOverdueActions() {
+	ticker := time.NewTicker(actionReminderInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rs.sendOverdueActionReminders()
+	}
+}
+
+// sendOverdueActionReminders sends each assignee a single email of their overdue actions, only once per due date.
+// Reminders are only marked as sent for the assignees whose email was sent so failed ones are retried
+func (rs *Service) sendOverdueActionReminders() {
+	if rs.email == nil {
+		return
+	}
+
+	Reminders, err := rs.db.GetOverdueRetroActionReminders()
+	if err != nil || len(Reminders) == 0 {
+		return
+	}
+
+	var byUser = make(map[string][]*model.RetroActionReminder)
+	var userEmails = make([]string, 0)
+	for _, r := range Reminders {
+		if _, ok := byUser[r.UserEmail]; !ok {
+			userEmails = append(userEmails, r.UserEmail)
+		}
+		byUser[r.UserEmail] = append(byUser[r.UserEmail], r)
+	}
+
+	var Reminded = make([]*model.RetroActionReminder, 0)
+	for _, UserEmail := range userEmails {
+		Actions := byUser[UserEmail]
+		if err := rs.email.SendOverdueRetroActions(Actions[0].UserName, UserEmail, Actions); err != nil {
+			continue
+		}
+		Reminded = append(Reminded, Actions...)
+	}
+
+	if len(Reminded) == 0 {
+		return
+	}
+	if err := rs.db.SetRetroActionsReminded(Reminded); err != nil {
+		rs.logger.Error("set retro actions reminded error", zap.Error(err))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/StevenWeathers/thunderdome-planning-poker/db"
+	"github.com/StevenWeathers/thunderdome-planning-poker/email"
 	"go.uber.org/zap"
 )
 
@@ -11,42 +12,60 @@ import (
 type Service struct {
 	db                    *db.Database
 	logger                *zap.Logger
+	email                 *email.Email
 	validateSessionCookie func(w http.ResponseWriter, r *http.Request) (string, error)
 	validateUserCookie    func(w http.ResponseWriter, r *http.Request) (string, error)
 	eventHandlers         map[string]func(string, string, string) ([]byte, error, bool)
+	// voteEventHandlers report when votes are hidden so their event is only sent to the voting user
+	voteEventHandlers map[string]func(string, string, string) ([]byte, bool, error)
 }
 
 // New returns a new retro with websocket hub/client and event handlers
 func New(
 	db *db.Database,
 	logger *zap.Logger,
+	email *email.Email,
+	ActionRemindersEnabled bool,
 	validateSessionCookie func(w http.ResponseWriter, r *http.Request) (string, error),
 	validateUserCookie func(w http.ResponseWriter, r *http.Request) (string, error),
 ) *Service {
 	rs := &Service{
 		db:                    db,
 		logger:                logger,
+		email:                 email,
 		validateSessionCookie: validateSessionCookie,
 		validateUserCookie:    validateUserCookie,
 	}
 
 	rs.eventHandlers = map[string]func(string, string, string) ([]byte, error, bool){
-		"create_item":         rs.CreateItem,
-		"group_item":          rs.GroupItem,
-		"group_name_change":   rs.GroupNameChange,
+		"create_item":            rs.CreateItem,
+		"group_item":             rs.GroupItem,
+		"group_name_change":      rs.GroupNameChange,
+		"delete_item":            rs.DeleteItem,
+		"create_action":          rs.CreateAction,
+		"update_action":          rs.UpdateAction,
+		"delete_action":          rs.DeleteAction,
+		"add_action_comment":     rs.ActionCommentAdd,
+		"edit_action_comment":    rs.ActionCommentEdit,
+		"delete_action_comment":  rs.ActionCommentDelete,
+		"add_action_assignee":    rs.ActionAssigneeAdd,
+		"delete_action_assignee": rs.ActionAssigneeDelete,
+		"review_action":          rs.ReviewAction,
+		"advance_phase":          rs.AdvancePhase,
+		"edit_retro":             rs.EditRetro,
+		"concede_retro":          rs.Delete,
+		"abandon_retro":          rs.Abandon,
+	}
+
+	rs.voteEventHandlers = map[string]func(string, string, string) ([]byte, bool, error){
 		"group_vote":          rs.GroupUserVote,
 		"group_vote_subtract": rs.GroupUserSubtractVote,
-		"delete_item":         rs.DeleteItem,
-		"create_action":       rs.CreateAction,
-		"update_action":       rs.UpdateAction,
-		"delete_action":       rs.DeleteAction,
-		"advance_phase":       rs.AdvancePhase,
-		"edit_retro":          rs.EditRetro,
-		"concede_retro":       rs.Delete,
-		"abandon_retro":       rs.Abandon,
 	}
 
 	go h.run()
+	if ActionRemindersEnabled {
+		go rs.watchOverdueActions()
+	}
 
 	return rs
 }
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/api/retro"
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type actionCommentRequestBody struct {
+	Comment string `json:"comment" validate:"required" example:"this is blocked by the platform upgrade"`
+}
+
+type actionAssigneeRequestBody struct {
+	UserID string `json:"userId" validate:"required,uuid"`
+}
+
+// retroActionEvent resolves the team retro the action belongs to and sends the event to it
+func (a *api) retroActionEvent(w http.ResponseWriter, r *http.Request, rs *retro.Service, EventType string, EventValue interface{}) {
+	vars := mux.Vars(r)
+	UserID := r.Context().Value(contextKeyUserID).(string)
+
+	RetroID, err := a.db.GetTeamRetroActionRetroID(vars["teamId"], vars["actionId"])
+	if err != nil {
+		a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+		return
+	}
+
+	value, _ := json.Marshal(EventValue)
+	if err := rs.APIEvent(RetroID, UserID, EventType, string(value)); err != nil {
+		a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+		return
+	}
+
+	a.Success(w, r, http.StatusOK, nil, nil)
+}
+
+// handleRetroActionCommentAdd handles adding a comment to a team retro action
+// @Summary Add Retro Action Comment
+// @Description Adds a comment to the retro action, same as the add_action_comment websocket event
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param actionId path string true "the action ID"
+// @Param comment body actionCommentRequestBody true "action comment object"
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/retro-actions/{actionId}/comments [post]
+// @Router /{orgId}/teams/{teamId}/retro-actions/{actionId}/comments [post]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/comments [post]
+func (a *api) handleRetroActionCommentAdd(rs *retro.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c = actionCommentRequestBody{}
+		if !a.getActionRequestBody(w, r, &c) {
+			return
+		}
+
+		a.retroActionEvent(w, r, rs, "add_action_comment", map[string]string{
+			"actionId": mux.Vars(r)["actionId"],
+			"comment":  c.Comment,
+		})
+	}
+}
+
+// handleRetroActionCommentEdit handles editing a team retro action comment
+// @Summary Edit Retro Action Comment
+// @Description Edits the retro action comment, only its author can edit it, same as the edit_action_comment websocket event
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param actionId path string true "the action ID"
+// @Param commentId path string true "the comment ID"
+// @Param comment body actionCommentRequestBody true "action comment object"
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/retro-actions/{actionId}/comments/{commentId} [put]
+// @Router /{orgId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId} [put]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId} [put]
+func (a *api) handleRetroActionCommentEdit(rs *retro.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c = actionCommentRequestBody{}
+		if !a.getActionRequestBody(w, r, &c) {
+			return
+		}
+
+		vars := mux.Vars(r)
+		a.retroActionEvent(w, r, rs, "edit_action_comment", map[string]string{
+			"actionId":  vars["actionId"],
+			"commentId": vars["commentId"],
+			"comment":   c.Comment,
+		})
+	}
+}
+
+// handleRetroActionCommentDelete handles deleting a team retro action comment
+// @Summary Delete Retro Action Comment
+// @Description Deletes the retro action comment, either by its author or the retro owner, same as the delete_action_comment websocket event
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param actionId path string true "the action ID"
+// @Param commentId path string true "the comment ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/retro-actions/{actionId}/comments/{commentId} [delete]
+// @Router /{orgId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/comments/{commentId} [delete]
+func (a *api) handleRetroActionCommentDelete(rs *retro.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.retroActionEvent(w, r, rs, "delete_action_comment", map[string]string{
+			"actionId":  vars["actionId"],
+			"commentId": vars["commentId"],
+		})
+	}
+}
+
+// handleRetroActionAssigneeAdd handles assigning a team member to a team retro action
+// @Summary Add Retro Action Assignee
+// @Description Assigns the team member to the retro action, same as the add_action_assignee websocket event
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param actionId path string true "the action ID"
+// @Param assignee body actionAssigneeRequestBody true "action assignee object"
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/retro-actions/{actionId}/assignees [post]
+// @Router /{orgId}/teams/{teamId}/retro-actions/{actionId}/assignees [post]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/assignees [post]
+func (a *api) handleRetroActionAssigneeAdd(rs *retro.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var as = actionAssigneeRequestBody{}
+		if !a.getActionRequestBody(w, r, &as) {
+			return
+		}
+
+		vars := mux.Vars(r)
+		if _, err := a.db.TeamUserRole(as.UserID, vars["teamId"]); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "ASSIGNEE_NOT_TEAM_MEMBER"))
+			return
+		}
+
+		a.retroActionEvent(w, r, rs, "add_action_assignee", map[string]string{
+			"actionId": vars["actionId"],
+			"userId":   as.UserID,
+		})
+	}
+}
+
+// handleRetroActionAssigneeDelete handles removing an assignee from a team retro action
+// @Summary Delete Retro Action Assignee
+// @Description Removes the assignee from the retro action, same as the delete_action_assignee websocket event
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param actionId path string true "the action ID"
+// @Param userId path string true "the assignees user ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/retro-actions/{actionId}/assignees/{userId} [delete]
+// @Router /{orgId}/teams/{teamId}/retro-actions/{actionId}/assignees/{userId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-actions/{actionId}/assignees/{userId} [delete]
+func (a *api) handleRetroActionAssigneeDelete(rs *retro.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		a.retroActionEvent(w, r, rs, "delete_action_assignee", map[string]string{
+			"actionId": vars["actionId"],
+			"userId":   vars["userId"],
+		})
+	}
+}
+
+// getActionRequestBody reads and validates the retro action request body, writing the failure when it's invalid
+func (a *api) getActionRequestBody(w http.ResponseWriter, r *http.Request, Body interface{}) bool {
+	body, bodyErr := ioutil.ReadAll(r.Body)
+	if bodyErr != nil {
+		a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+		return false
+	}
+
+	if jsonErr := json.Unmarshal(body, Body); jsonErr != nil {
+		a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+		return false
+	}
+
+	v := validator.New()
+	if err := v.Struct(Body); err != nil {
+		a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type retroColumnRequestBody struct {
+	Key    string `json:"key" validate:"required,max=16" example:"start"`
+	Name   string `json:"name" validate:"required,max=64" example:"Start"`
+	Color  string `json:"color" validate:"max=32" example:"green"`
+	Icon   string `json:"icon" validate:"max=32"`
+	Prompt string `json:"prompt" validate:"max=256" example:"What should we start doing?"`
+}
+
+type retroTemplateRequestBody struct {
+	Name    string                    `json:"name" validate:"required,max=256"`
+	Columns []*retroColumnRequestBody `json:"columns" validate:"required,min=1,max=10,dive"`
+}
+
+// getRetroColumnsFromRequest converts the requested columns to retro columns ensuring their keys are unique
+func getRetroColumnsFromRequest(Columns []*retroColumnRequestBody) ([]*model.RetroColumn, error) {
+	var keys = make(map[string]bool)
+	var RetroColumns = make([]*model.RetroColumn, 0, len(Columns))
+
+	for _, c := range Columns {
+		if keys[c.Key] {
+			return nil, Errorf(EINVALID, "DUPLICATE_RETRO_COLUMN_KEY")
+		}
+		keys[c.Key] = true
+		RetroColumns = append(RetroColumns, &model.RetroColumn{
+			Key:    c.Key,
+			Name:   c.Name,
+			Color:  c.Color,
+			Icon:   c.Icon,
+			Prompt: c.Prompt,
+		})
+	}
+
+	return RetroColumns, nil
+}
+
+// getRetroTemplateForEntity gets the retro template by ID confirming it belongs to the team or user in the route
+func (a *api) getRetroTemplateForEntity(r *http.Request) (*model.RetroTemplate, error) {
+	vars := mux.Vars(r)
+
+	Template, err := a.db.RetroTemplateGet(vars["templateId"])
+	if err != nil {
+		return nil, Errorf(ENOTFOUND, err.Error())
+	}
+
+	if TeamID, ok := vars["teamId"]; ok {
+		if Template.TeamId != TeamID {
+			return nil, Errorf(ENOTFOUND, "RETRO_TEMPLATE_NOT_FOUND")
+		}
+	} else if Template.TeamId != "" || Template.UserId != vars["userId"] {
+		return nil, Errorf(ENOTFOUND, "RETRO_TEMPLATE_NOT_FOUND")
+	}
+
+	return Template, nil
+}
+
+// handleRetroTemplatesGet gets a list of retro templates for the user or team
+// @Summary Get Retro Templates
+// @Description Get a list of retro templates for the user or team
+// @Tags retro
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Success 200 object standardJsonResponse{data=[]model.RetroTemplate}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/retro-templates [get]
+// @Router /teams/{teamId}/retro-templates [get]
+// @Router /{orgId}/teams/{teamId}/retro-templates [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-templates [get]
+func (a *api) handleRetroTemplatesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var Templates []*model.RetroTemplate
+		var err error
+		if TeamID, ok := vars["teamId"]; ok {
+			Templates, err = a.db.RetroTemplateListByTeam(TeamID)
+		} else {
+			Templates, err = a.db.RetroTemplateListByUser(vars["userId"])
+		}
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Templates, nil)
+	}
+}
+
+// handleRetroTemplateCreate handles creating a retro template with custom columns for the user or team
+// @Summary Create Retro Template
+// @Description Creates a retro template for the user or team with a configurable list of columns
+// @Tags retro
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param template body retroTemplateRequestBody true "new retro template object"
+// @Success 200 object standardJsonResponse{data=model.RetroTemplate}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/retro-templates [post]
+// @Router /teams/{teamId}/retro-templates [post]
+// @Router /{orgId}/teams/{teamId}/retro-templates [post]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-templates [post]
+func (a *api) handleRetroTemplateCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var t = retroTemplateRequestBody{}
+		if jsonErr := json.Unmarshal(body, &t); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		v := validator.New()
+		if err := v.Struct(t); err != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+			return
+		}
+
+		Columns, err := getRetroColumnsFromRequest(t.Columns)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var UserID string
+		TeamID, ok := vars["teamId"]
+		if !ok {
+			UserID = vars["userId"]
+		}
+
+		Template, err := a.db.RetroTemplateCreate(UserID, TeamID, t.Name, Columns)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Template, nil)
+	}
+}
+
+// handleRetroTemplateDelete handles deleting a retro template
+// @Summary Delete Retro Template
+// @Description Deletes a retro template
+// @Tags retro
+// @Produce  json
+// @Param userId path string false "the user ID"
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string false "the team ID"
+// @Param templateId path string true "the retro template ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /users/{userId}/retro-templates/{templateId} [delete]
+// @Router /teams/{teamId}/retro-templates/{templateId} [delete]
+// @Router /{orgId}/teams/{teamId}/retro-templates/{templateId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/retro-templates/{templateId} [delete]
+func (a *api) handleRetroTemplateDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		Template, err := a.getRetroTemplateForEntity(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		if err := a.db.RetroTemplateDelete(Template.Id); err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,352 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// sprintDateLayout the layout of sprint start and end dates
+const sprintDateLayout = "2006-01-02"
+
+type sprintRequestBody struct {
+	Name      string  `json:"name" validate:"required,max=256"`
+	StartDate string  `json:"startDate" validate:"required" example:"2022-07-18"`
+	EndDate   string  `json:"endDate" validate:"required" example:"2022-07-29"`
+	Capacity  float64 `json:"capacity" validate:"min=0"`
+}
+
+type sprintPlanDoneRequestBody struct {
+	Done bool `json:"done"`
+}
+
+// getSprintRequestBody reads and validates the sprint request body returning the parsed start and end dates
+func (a *api) getSprintRequestBody(r *http.Request) (*sprintRequestBody, time.Time, time.Time, error) {
+	var s = &sprintRequestBody{}
+	var StartDate, EndDate time.Time
+
+	body, bodyErr := ioutil.ReadAll(r.Body)
+	if bodyErr != nil {
+		return nil, StartDate, EndDate, Errorf(EINVALID, bodyErr.Error())
+	}
+	if jsonErr := json.Unmarshal(body, s); jsonErr != nil {
+		return nil, StartDate, EndDate, Errorf(EINVALID, jsonErr.Error())
+	}
+
+	v := validator.New()
+	if err := v.Struct(s); err != nil {
+		return nil, StartDate, EndDate, Errorf(EINVALID, err.Error())
+	}
+
+	StartDate, startErr := time.Parse(sprintDateLayout, s.StartDate)
+	EndDate, endErr := time.Parse(sprintDateLayout, s.EndDate)
+	if startErr != nil || endErr != nil || EndDate.Before(StartDate) {
+		return nil, StartDate, EndDate, Errorf(EINVALID, "INVALID_SPRINT_DATES")
+	}
+
+	return s, StartDate, EndDate, nil
+}
+
+// handleSprintsGet gets a list of the teams sprints
+// @Summary Get Team Sprints
+// @Description Get a list of the teams sprints with their committed plans, most recent first
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Success 200 object standardJsonResponse{data=[]model.Sprint}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints [get]
+// @Router /{orgId}/teams/{teamId}/sprints [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints [get]
+func (a *api) handleSprintsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		Sprints, err := a.db.SprintListByTeam(vars["teamId"])
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprints, nil)
+	}
+}
+
+// handleSprintGet gets the teams sprint
+// @Summary Get Team Sprint
+// @Description Get the teams sprint with its committed plans
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Success 200 object standardJsonResponse{data=model.Sprint}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId} [get]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId} [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId} [get]
+func (a *api) handleSprintGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		Sprint, err := a.db.SprintGet(vars["teamId"], vars["sprintId"])
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprint, nil)
+	}
+}
+
+// handleSprintCreate handles creating a sprint for the team
+// @Summary Create Team Sprint
+// @Description Creates a sprint for the team
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprint body sprintRequestBody true "new sprint object"
+// @Success 200 object standardJsonResponse{data=model.Sprint}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints [post]
+// @Router /{orgId}/teams/{teamId}/sprints [post]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints [post]
+func (a *api) handleSprintCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		s, StartDate, EndDate, err := a.getSprintRequestBody(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		Sprint, err := a.db.SprintCreate(vars["teamId"], s.Name, StartDate, EndDate, s.Capacity)
+		if err != nil {
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprint, nil)
+	}
+}
+
+// handleSprintUpdate handles updating the teams sprint
+// @Summary Update Team Sprint
+// @Description Updates the teams sprint name, dates and capacity
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Param sprint body sprintRequestBody true "updated sprint object"
+// @Success 200 object standardJsonResponse{data=model.Sprint}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId} [put]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId} [put]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId} [put]
+func (a *api) handleSprintUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		s, StartDate, EndDate, err := a.getSprintRequestBody(r)
+		if err != nil {
+			a.Failure(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		Sprint, err := a.db.SprintUpdate(vars["teamId"], vars["sprintId"], s.Name, StartDate, EndDate, s.Capacity)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprint, nil)
+	}
+}
+
+// handleSprintDelete handles deleting the teams sprint
+// @Summary Delete Team Sprint
+// @Description Deletes the teams sprint, the committed plans remain in their battles
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Success 200 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId} [delete]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId} [delete]
+func (a *api) handleSprintDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		if err := a.db.SprintDelete(vars["teamId"], vars["sprintId"]); err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, nil, nil)
+	}
+}
+
+// handleSprintPlanCommit handles committing a plan from one of the teams battles to the sprint
+// @Summary Commit Plan to Sprint
+// @Description Commits a plan from any of the teams battles to the sprint
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Param planId path string true "the plan ID"
+// @Success 200 object standardJsonResponse{data=model.Sprint}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId}/plans/{planId} [put]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId}/plans/{planId} [put]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/plans/{planId} [put]
+func (a *api) handleSprintPlanCommit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		Sprint, err := a.db.SprintCommitPlan(vars["teamId"], vars["sprintId"], vars["planId"])
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprint, nil)
+	}
+}
+
+// handleSprintPlanUncommit handles removing a committed plan from the sprint
+// @Summary Uncommit Plan from Sprint
+// @Description Removes a committed plan from the sprint
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Param planId path string true "the plan ID"
+// @Success 200 object standardJsonResponse{data=model.Sprint}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId}/plans/{planId} [delete]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId}/plans/{planId} [delete]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/plans/{planId} [delete]
+func (a *api) handleSprintPlanUncommit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		Sprint, err := a.db.SprintUncommitPlan(vars["teamId"], vars["sprintId"], vars["planId"])
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprint, nil)
+	}
+}
+
+// handleSprintPlanDone handles marking the sprints committed plan as done or not done
+// @Summary Set Sprint Plan Done
+// @Description Sets whether the sprints committed plan is done, feeding the sprint burn-up
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Param planId path string true "the plan ID"
+// @Param done body sprintPlanDoneRequestBody true "done object"
+// @Success 200 object standardJsonResponse{data=model.Sprint}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId}/plans/{planId}/done [put]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}/done [put]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/plans/{planId}/done [put]
+func (a *api) handleSprintPlanDone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		body, bodyErr := ioutil.ReadAll(r.Body)
+		if bodyErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, bodyErr.Error()))
+			return
+		}
+
+		var d = sprintPlanDoneRequestBody{}
+		if jsonErr := json.Unmarshal(body, &d); jsonErr != nil {
+			a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, jsonErr.Error()))
+			return
+		}
+
+		Sprint, err := a.db.SprintSetPlanDone(vars["teamId"], vars["sprintId"], vars["planId"], d.Done)
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Sprint, nil)
+	}
+}
+
+// handleSprintBurnup gets the sprints burn-up
+// @Summary Get Sprint Burn-up
+// @Description Get the sprints committed scope and completed points per day along with its capacity
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param sprintId path string true "the sprint ID"
+// @Success 200 object standardJsonResponse{data=model.SprintBurnup}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 404 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/sprints/{sprintId}/burnup [get]
+// @Router /{orgId}/teams/{teamId}/sprints/{sprintId}/burnup [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/sprints/{sprintId}/burnup [get]
+func (a *api) handleSprintBurnup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		Burnup, err := a.db.SprintBurnup(vars["teamId"], vars["sprintId"])
+		if err != nil {
+			a.Failure(w, r, http.StatusNotFound, Errorf(ENOTFOUND, err.Error()))
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Burnup, nil)
+	}
+}
@@ -211,6 +211,12 @@ func (b *Service) ServeWs() http.HandlerFunc {
 		}
 		c := &connection{send: make(chan []byte, 256), ws: ws}
 
+		// observers authenticate with a read-only token instead of a user session
+		if ObserverToken := r.URL.Query().Get("observerToken"); ObserverToken != "" {
+			b.serveObserver(c, storyboardID, ObserverToken)
+			return
+		}
+
 		SessionId, cookieErr := b.validateSessionCookie(w, r)
 		if cookieErr != nil && cookieErr.Error() != "NO_SESSION_COOKIE" {
 			b.handleSocketClose(ws, 4001, "unauthorized")
@@ -0,0 +1,69 @@
+package storyboard
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// observerEntityType the observer token entity type for storyboards
+const observerEntityType = "storyboard"
+
+// serveObserver subscribes a read-only observer to the storyboard, observers receive every broadcast
+// but are never added as a storyboard user and any events they send are dropped
+func (b *Service) serveObserver(c *connection, StoryboardID string, ObserverToken string) {
+	if err := b.db.ConfirmObserverToken(observerEntityType, StoryboardID, ObserverToken); err != nil {
+		b.handleSocketClose(c.ws, 4001, "unauthorized")
+		return
+	}
+
+	storyboard, storyboardErr := b.db.GetStoryboard(StoryboardID)
+	if storyboardErr != nil {
+		b.handleSocketClose(c.ws, 4004, "storyboard not found")
+		return
+	}
+	storyboard.JoinCode = ""
+
+	ss := subscription{c, StoryboardID, ""}
+	h.register <- ss
+
+	Storyboard, _ := json.Marshal(storyboard)
+	initEvent := createSocketEvent("init", string(Storyboard), "")
+	_ = c.write(websocket.TextMessage, initEvent)
+
+	go ss.writePump()
+	go ss.observerReadPump(b, ObserverToken)
+}
+
+// observerReadPump keeps the observer connection open until it closes or its token is revoked
+func (sub subscription) observerReadPump(b *Service, ObserverToken string) {
+	c := sub.conn
+
+	defer func() {
+		h.unregister <- sub
+		if err := c.ws.Close(); err != nil {
+			b.logger.Error("close error", zap.Error(err))
+		}
+	}()
+	c.ws.SetReadLimit(maxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		if err := b.db.ConfirmObserverToken(observerEntityType, sub.arena, ObserverToken); err != nil {
+			return errors.New("OBSERVER_TOKEN_REVOKED")
+		}
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+				b.logger.Error("unexpected close error", zap.Error(err))
+			}
+			break
+		}
+	}
+}
@@ -374,7 +374,12 @@ func (a *api) handleTeamRemoveStoryboard() http.HandlerFunc {
 // @Produce  json
 // @Param limit query int false "Max number of results to return"
 // @Param offset query int false "Starting point to return rows from, should be multiplied by limit or 0"
-// @Param completed query boolean false "Only completed retro actions"
+// @Param completed query boolean false "Only completed retro actions, ignored when filtering by status"
+// @Param status query string false "Only retro actions with the status" Enums(open, in_progress, blocked, done, dropped)
+// @Param priority query string false "Only retro actions with the priority" Enums(low, medium, high)
+// @Param assignee query string false "Only retro actions assigned to the user ID"
+// @Param overdue query boolean false "Only incomplete retro actions past their due date"
+// @Param sort query string false "Sort by newest, nearest due date or highest priority, defaults to created" Enums(created, due, priority)
 // @Success 200 object standardJsonResponse{data=[]model.RetroAction}
 // @Failure 500 object standardJsonResponse{}
 // @Security ApiKeyAuth
@@ -389,8 +394,12 @@ func (a *api) handleGetTeamRetroActions() http.HandlerFunc {
 		var Actions []*model.RetroAction
 		query := r.URL.Query()
 		Completed, _ := strconv.ParseBool(query.Get("completed"))
+		Overdue, _ := strconv.ParseBool(query.Get("overdue"))
 
-		Actions, Count, err = a.db.GetTeamRetroActions(TeamID, Limit, Offset, Completed)
+		Actions, Count, err = a.db.GetTeamRetroActions(
+			TeamID, Limit, Offset, Completed,
+			query.Get("status"), query.Get("priority"), query.Get("assignee"), Overdue, query.Get("sort"),
+		)
 
 		if err != nil {
 			a.Failure(w, r, http.StatusInternalServerError, err)
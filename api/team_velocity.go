@@ -0,0 +1,86 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultVelocityPeriods the number of periods of velocity history used by default
+	defaultVelocityPeriods = 12
+	// maxVelocityPeriods the most periods of velocity history that can be requested
+	maxVelocityPeriods = 104
+	// maxVelocityPeriodWeeks the longest period (sprint) in weeks
+	maxVelocityPeriodWeeks = 8
+)
+
+// handleTeamVelocity gets the teams velocity with an optional Monte Carlo forecast
+// @Summary Get Team Velocity
+// @Description Get the points finalized in the teams battles per week or sprint,
+// @Description with a Monte Carlo forecast of the weeks to finish the points at 50/85/95% confidence when points is provided
+// @Tags team
+// @Produce  json
+// @Param orgId path string false "the organization ID"
+// @Param departmentId path string false "the department ID"
+// @Param teamId path string true "the team ID"
+// @Param periodWeeks query int false "the weeks in each period e.g. 2 for two week sprints, defaults to 1"
+// @Param periods query int false "the number of complete periods of history to use, defaults to 12"
+// @Param points query number false "the points to forecast finishing, no more than the best period velocity finishes in 520 periods"
+// @Success 200 object standardJsonResponse{data=model.TeamVelocity}
+// @Failure 400 object standardJsonResponse{}
+// @Failure 403 object standardJsonResponse{}
+// @Failure 500 object standardJsonResponse{}
+// @Security ApiKeyAuth
+// @Router /teams/{teamId}/velocity [get]
+// @Router /{orgId}/teams/{teamId}/velocity [get]
+// @Router /{orgId}/departments/{departmentId}/teams/{teamId}/velocity [get]
+func (a *api) handleTeamVelocity() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		query := r.URL.Query()
+		PeriodWeeks := 1
+		Periods := defaultVelocityPeriods
+		var Points float64
+
+		if pw := query.Get("periodWeeks"); pw != "" {
+			var err error
+			PeriodWeeks, err = strconv.Atoi(pw)
+			if err != nil || PeriodWeeks < 1 || PeriodWeeks > maxVelocityPeriodWeeks {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_PERIOD_WEEKS"))
+				return
+			}
+		}
+		if p := query.Get("periods"); p != "" {
+			var err error
+			Periods, err = strconv.Atoi(p)
+			if err != nil || Periods < 1 || Periods > maxVelocityPeriods {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_PERIODS"))
+				return
+			}
+		}
+		if p := query.Get("points"); p != "" {
+			var err error
+			Points, err = strconv.ParseFloat(p, 64)
+			if err != nil || math.IsNaN(Points) || math.IsInf(Points, 0) || Points <= 0 {
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, "INVALID_FORECAST_POINTS"))
+				return
+			}
+		}
+
+		Velocity, err := a.db.TeamVelocity(vars["teamId"], PeriodWeeks, Periods, Points)
+		if err != nil {
+			switch err.Error() {
+			case "NOT_ENOUGH_VELOCITY_HISTORY", "FORECAST_BEYOND_HORIZON", "INVALID_FORECAST_POINTS":
+				a.Failure(w, r, http.StatusBadRequest, Errorf(EINVALID, err.Error()))
+				return
+			}
+			a.Failure(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.Success(w, r, http.StatusOK, Velocity, nil)
+	}
+}
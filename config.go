@@ -59,6 +59,9 @@ func InitConfig(logger *zap.Logger) {
 	viper.SetDefault("config.cleanup_retros_days_old", 180)
 	viper.SetDefault("config.cleanup_storyboards_days_old", 180)
 	viper.SetDefault("config.organizations_enabled", true)
+	viper.SetDefault("config.leader_failover", "none")
+	viper.SetDefault("config.leader_failover_grace_seconds", 60)
+	viper.SetDefault("config.retro_action_reminders", true)
 
 	// feature flags
 	viper.SetDefault("feature.poker", true)
@@ -122,6 +125,9 @@ func InitConfig(logger *zap.Logger) {
 	viper.BindEnv("config.cleanup_retros_days_old", "CONFIG_CLEANUP_RETROS_DAYS_OLD")
 	viper.BindEnv("config.cleanup_storyboards_days_old", "CONFIG_CLEANUP_STORYBOARDS_DAYS_OLD")
 	viper.BindEnv("config.organizations_enabled", "CONFIG_ORGANIZATIONS_ENABLED")
+	viper.BindEnv("config.leader_failover", "CONFIG_LEADER_FAILOVER")
+	viper.BindEnv("config.leader_failover_grace_seconds", "CONFIG_LEADER_FAILOVER_GRACE_SECONDS")
+	viper.BindEnv("config.retro_action_reminders", "CONFIG_RETRO_ACTION_REMINDERS")
 
 	viper.BindEnv("feature.poker", "FEATURE_POKER")
 	viper.BindEnv("feature.retro", "FEATURE_RETRO")
@@ -143,4 +149,11 @@ func InitConfig(logger *zap.Logger) {
 			logger.Fatal(err.Error())
 		}
 	}
+
+	if failover := viper.GetString("config.leader_failover"); failover != "promote" && failover != "none" {
+		logger.Fatal("config.leader_failover must be either promote or none, got " + failover)
+	}
+	if viper.GetInt("config.leader_failover_grace_seconds") < 0 {
+		logger.Fatal("config.leader_failover_grace_seconds must not be negative")
+	}
 }
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// GetBattleBreakouts gets the battles breakout groups with their users and plans
+func (d *Database) GetBattleBreakouts(BattleID string) []*model.BattleBreakout {
+	var Breakouts = make([]*model.BattleBreakout, 0)
+
+	rows, err := d.db.Query(
+		`SELECT bb.id, bb.name, COALESCE(bb.leader_id::TEXT, ''), COALESCE(bb.active_plan_id::TEXT, ''),
+			COALESCE((SELECT json_agg(bu.user_id) FROM battles_users bu WHERE bu.breakout_id = bb.id), '[]'),
+			COALESCE((SELECT json_agg(p.id ORDER BY p.sort_order) FROM plans p WHERE p.breakout_id = bb.id), '[]')
+		FROM battle_breakout bb
+		WHERE bb.battle_id = $1
+		ORDER BY bb.created_date, bb.name;`,
+		BattleID,
+	)
+	if err != nil {
+		d.logger.Error("get battle breakouts query error", zap.Error(err))
+		return Breakouts
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bo = &model.BattleBreakout{}
+		var userIds string
+		var planIds string
+		if err := rows.Scan(&bo.Id, &bo.Name, &bo.LeaderId, &bo.ActivePlanId, &userIds, &planIds); err != nil {
+			d.logger.Error("battle breakout row scan error", zap.Error(err))
+			continue
+		}
+		_ = json.Unmarshal([]byte(userIds), &bo.UserIds)
+		_ = json.Unmarshal([]byte(planIds), &bo.PlanIds)
+		Breakouts = append(Breakouts, bo)
+	}
+
+	return Breakouts
+}
+
+// StartBattleBreakouts splits the battles users and plans into the breakout groups in a single transaction
+func (d *Database) StartBattleBreakouts(BattleID string, Breakouts []*model.BattleBreakout) ([]*model.BattleBreakout, error) {
+	var started bool
+	if err := d.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM battle_breakout WHERE battle_id = $1);`, BattleID,
+	).Scan(&started); err != nil {
+		d.logger.Error("get battle breakouts started query error", zap.Error(err))
+		return nil, errors.New("unable to start breakouts")
+	}
+	if started {
+		return nil, errors.New("BREAKOUTS_ALREADY_STARTED")
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		d.logger.Error("start breakouts transaction error", zap.Error(err))
+		return nil, errors.New("unable to start breakouts")
+	}
+
+	for _, bo := range Breakouts {
+		var BreakoutID string
+		if err := tx.QueryRow(
+			`INSERT INTO battle_breakout (battle_id, name, leader_id) VALUES ($1, $2, $3) RETURNING id;`,
+			BattleID, bo.Name, bo.LeaderId,
+		).Scan(&BreakoutID); err != nil {
+			d.logger.Error("create battle breakout query error", zap.Error(err))
+			tx.Rollback()
+			return nil, errors.New("unable to start breakouts")
+		}
+
+		userIds, _ := json.Marshal(bo.UserIds)
+		planIds, _ := json.Marshal(bo.PlanIds)
+		if _, err := tx.Exec(
+			`UPDATE battles_users SET breakout_id = $2
+			WHERE battle_id = $1 AND user_id::TEXT IN (SELECT jsonb_array_elements_text($3::jsonb));`,
+			BattleID, BreakoutID, string(userIds),
+		); err != nil {
+			d.logger.Error("set battle breakout users query error", zap.Error(err))
+			tx.Rollback()
+			return nil, errors.New("unable to start breakouts")
+		}
+		res, err := tx.Exec(
+			`UPDATE plans SET breakout_id = $2
+			WHERE battle_id = $1 AND active = false AND id::TEXT IN (SELECT jsonb_array_elements_text($3::jsonb));`,
+			BattleID, BreakoutID, string(planIds),
+		)
+		if err != nil {
+			d.logger.Error("set battle breakout plans query error", zap.Error(err))
+			tx.Rollback()
+			return nil, errors.New("unable to start breakouts")
+		}
+		if rows, _ := res.RowsAffected(); int(rows) != len(bo.PlanIds) {
+			tx.Rollback()
+			return nil, errors.New("INVALID_BREAKOUT_PLANS")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error("start breakouts commit error", zap.Error(err))
+		return nil, errors.New("unable to start breakouts")
+	}
+
+	return d.GetBattleBreakouts(BattleID), nil
+}
+
+// EndBattleBreakouts merges the breakout groups back into the battle,
+// voting still open in a breakout is ended so its results are kept
+func (d *Database) EndBattleBreakouts(BattleID string) ([]*model.Plan, error) {
+	for _, p := range d.GetPlans(BattleID, "") {
+		if p.Active && p.BreakoutId != "" {
+			if _, err := d.EndPlanVoting(BattleID, p.Id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := d.db.Exec(
+		`DELETE FROM battle_breakout WHERE battle_id = $1;`, BattleID,
+	); err != nil {
+		d.logger.Error("end battle breakouts query error", zap.Error(err))
+		return nil, errors.New("unable to end breakouts")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// ConfirmBreakoutLeader confirms the user is the sub-leader of the breakout the plan belongs to
+func (d *Database) ConfirmBreakoutLeader(BattleID string, UserID string, PlanID string) error {
+	var BreakoutID string
+
+	if err := d.db.QueryRow(
+		`SELECT bb.id FROM battle_breakout bb
+		JOIN plans p ON p.breakout_id = bb.id
+		WHERE bb.battle_id = $1 AND bb.leader_id = $2 AND p.id = $3;`,
+		BattleID, UserID, PlanID,
+	).Scan(&BreakoutID); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm breakout leader query error", zap.Error(err))
+		}
+		return errors.New("REQUIRES_BREAKOUT_LEADER")
+	}
+
+	return nil
+}
+
+// ConfirmBreakoutVoter confirms the user is in the same breakout group as the plan,
+// plans outside of a breakout are only voted on by users outside of one
+func (d *Database) ConfirmBreakoutVoter(BattleID string, UserID string, PlanID string) error {
+	var InBreakout bool
+
+	if err := d.db.QueryRow(
+		`SELECT p.breakout_id IS NOT DISTINCT FROM bu.breakout_id
+		FROM plans p
+		JOIN battles_users bu ON bu.battle_id = p.battle_id AND bu.user_id = $2
+		WHERE p.battle_id = $1 AND p.id = $3;`,
+		BattleID, UserID, PlanID,
+	).Scan(&InBreakout); err != nil || !InBreakout {
+		if err != nil && err != sql.ErrNoRows {
+			d.logger.Error("confirm breakout voter query error", zap.Error(err))
+		}
+		return errors.New("NOT_IN_BREAKOUT")
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// PrepareBattleDimensions validates the dimensions and formula, dimensions using an estimation scale
+// get their point values from the scale
+func (d *Database) PrepareBattleDimensions(Dimensions []*model.BattleDimension, Formula string) error {
+	var keys = make(map[string]float64)
+
+	for _, dim := range Dimensions {
+		if !validDimensionKey(dim.Key) {
+			return errors.New("INVALID_DIMENSION_KEY")
+		}
+		if _, ok := keys[dim.Key]; ok {
+			return errors.New("DUPLICATE_DIMENSION_KEY")
+		}
+		keys[dim.Key] = 1
+
+		if dim.Name == "" {
+			dim.Name = dim.Key
+		}
+		if dim.EstimationScaleID != "" {
+			Scale, err := d.EstimationScaleGet(dim.EstimationScaleID)
+			if err != nil {
+				return err
+			}
+			dim.PointValuesAllowed = estimationScalePointValues(Scale)
+		}
+		if len(dim.PointValuesAllowed) == 0 {
+			return errors.New("DIMENSION_POINT_VALUES_REQUIRED")
+		}
+	}
+
+	if strings.TrimSpace(Formula) != "" {
+		if len(Dimensions) == 0 {
+			return errors.New("INVALID_DIMENSION_FORMULA")
+		}
+		if _, err := evaluateDimensionFormula(Formula, keys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetBattleDimensions sets the battles estimation dimensions and the formula combining their points into the plans points
+func (d *Database) SetBattleDimensions(BattleID string, Dimensions []*model.BattleDimension, Formula string) ([]*model.BattleDimension, error) {
+	if Dimensions == nil {
+		Dimensions = make([]*model.BattleDimension, 0)
+	}
+	if err := d.PrepareBattleDimensions(Dimensions, Formula); err != nil {
+		return nil, err
+	}
+	var dimensionsJSON, _ = json.Marshal(Dimensions)
+
+	if _, err := d.db.Exec(
+		`UPDATE battles SET updated_date = NOW(), dimensions = $2, dimension_formula = $3 WHERE id = $1;`,
+		BattleID,
+		string(dimensionsJSON),
+		strings.TrimSpace(Formula),
+	); err != nil {
+		d.logger.Error("update battle dimensions error", zap.Error(err))
+		return nil, errors.New("unable to set battle dimensions")
+	}
+
+	return Dimensions, nil
+}
+
+// GetBattleDimensions retrieves the battles estimation dimensions and formula
+func (d *Database) GetBattleDimensions(BattleID string) ([]*model.BattleDimension, string, error) {
+	var Dimensions = make([]*model.BattleDimension, 0)
+	var dimensions string
+	var Formula string
+
+	if err := d.db.QueryRow(
+		`SELECT dimensions, dimension_formula FROM battles WHERE id = $1;`,
+		BattleID,
+	).Scan(&dimensions, &Formula); err != nil {
+		d.logger.Error("get battle dimensions error", zap.Error(err))
+		return nil, "", errors.New("unable to retrieve battle dimensions")
+	}
+
+	if err := json.Unmarshal([]byte(dimensions), &Dimensions); err != nil {
+		d.logger.Error("battle dimensions json error", zap.Error(err))
+	}
+
+	return Dimensions, Formula, nil
+}
+
+// getBattleDimension finds the dimension by key
+func getBattleDimension(Dimensions []*model.BattleDimension, Key string) *model.BattleDimension {
+	for _, dim := range Dimensions {
+		if dim.Key == Key {
+			return dim
+		}
+	}
+
+	return nil
+}
+
+// dimensionWeights gets the numeric weights of the dimensions estimation scale values
+func (d *Database) dimensionWeights(Dimension *model.BattleDimension) map[string]float64 {
+	var Weights = make(map[string]float64)
+
+	if Dimension.EstimationScaleID == "" {
+		return Weights
+	}
+	Scale, err := d.EstimationScaleGet(Dimension.EstimationScaleID)
+	if err != nil {
+		return Weights
+	}
+	for _, v := range Scale.Values {
+		if v.Weight != nil {
+			Weights[v.Value] = *v.Weight
+		}
+	}
+
+	return Weights
+}
+
+// setPlanDimensionResults computes and stores the plans vote statistics for each dimension
+func (d *Database) setPlanDimensionResults(BattleID string, PlanID string, Votes []*model.Vote, Spectators map[string]bool, Rounding string) {
+	Dimensions, _, err := d.GetBattleDimensions(BattleID)
+	if err != nil || len(Dimensions) == 0 {
+		return
+	}
+
+	var Results = make(map[string]*model.PlanVoteResults)
+	for _, dim := range Dimensions {
+		var dimVotes = make([]*model.Vote, 0)
+		for _, v := range Votes {
+			if v.Dimension == dim.Key {
+				dimVotes = append(dimVotes, v)
+			}
+		}
+		Results[dim.Key] = calculateVoteResults(dimVotes, Spectators, dim.PointValuesAllowed, d.dimensionWeights(dim), Rounding)
+	}
+	resultsJSON, _ := json.Marshal(Results)
+
+	if _, err := d.db.Exec(
+		`UPDATE plans SET dimension_results = $2 WHERE id = $1;`, PlanID, string(resultsJSON),
+	); err != nil {
+		d.logger.Error("update plan dimension results error", zap.Error(err))
+	}
+}
+
+// calculateDimensionPoints combines the plans finalized dimension points into the plans points using the battles formula
+func (d *Database) calculateDimensionPoints(Dimensions []*model.BattleDimension, Formula string, Rounding string, DimensionPoints map[string]string) (string, error) {
+	var values = make(map[string]float64)
+
+	for _, dim := range Dimensions {
+		n, ok := voteNumericValue(DimensionPoints[dim.Key], d.dimensionWeights(dim))
+		if !ok {
+			return "", errors.New("DIMENSION_POINTS_NOT_NUMERIC")
+		}
+		values[dim.Key] = n
+	}
+
+	points, err := evaluateDimensionFormula(Formula, values)
+	if err != nil {
+		return "", err
+	}
+	if math.IsNaN(points) || math.IsInf(points, 0) {
+		return "", errors.New("INVALID_DIMENSION_FORMULA_RESULT")
+	}
+
+	switch Rounding {
+	case "round":
+		points = math.Round(points)
+	case "floor":
+		points = math.Floor(points)
+	default:
+		points = math.Ceil(points)
+	}
+
+	return strconv.FormatFloat(points, 'f', -1, 64), nil
+}
+
+// formulaParser a recursive descent parser for dimension formulas supporting
+// numbers, dimension keys, + - * / and parentheses
+type formulaParser struct {
+	input  string
+	pos    int
+	values map[string]float64
+}
+
+// evaluateDimensionFormula evaluates the formula substituting dimension keys with their values
+func evaluateDimensionFormula(Formula string, Values map[string]float64) (float64, error) {
+	p := &formulaParser{input: Formula, values: Values}
+
+	result, err := p.expression()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return 0, errors.New("INVALID_DIMENSION_FORMULA")
+	}
+
+	return result, nil
+}
+
+func (p *formulaParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *formulaParser) expression() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *formulaParser) term() (float64, error) {
+	left, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+
+		right, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			left *= right
+		} else {
+			left /= right
+		}
+	}
+}
+
+func (p *formulaParser) factor() (float64, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0, errors.New("INVALID_DIMENSION_FORMULA")
+	}
+
+	switch c := rune(p.input[p.pos]); {
+	case c == '-':
+		p.pos++
+		v, err := p.factor()
+		return -v, err
+	case c == '(':
+		p.pos++
+		v, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return 0, errors.New("INVALID_DIMENSION_FORMULA")
+		}
+		p.pos++
+		return v, nil
+	case unicode.IsDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return 0, errors.New("INVALID_DIMENSION_FORMULA")
+		}
+		return v, nil
+	case unicode.IsLetter(c) || c == '_':
+		start := p.pos
+		for p.pos < len(p.input) && isFormulaKeyChar(rune(p.input[p.pos])) {
+			p.pos++
+		}
+		v, ok := p.values[p.input[start:p.pos]]
+		if !ok {
+			return 0, errors.New("UNKNOWN_DIMENSION_IN_FORMULA")
+		}
+		return v, nil
+	}
+
+	return 0, errors.New("INVALID_DIMENSION_FORMULA")
+}
+
+// isFormulaKeyChar checks whether the character is allowed in a dimension key
+func isFormulaKeyChar(c rune) bool {
+	return c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_')
+}
+
+// validDimensionKey checks the dimension key can be referenced in a formula
+func validDimensionKey(Key string) bool {
+	if Key == "" || len(Key) > 64 || unicode.IsDigit(rune(Key[0])) {
+		return false
+	}
+
+	return strings.IndexFunc(Key, func(c rune) bool { return !isFormulaKeyChar(c) }) == -1
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+)
+
+// TestEvaluateDimensionFormula calls evaluateDimensionFormula and makes sure operator precedence,
+// parentheses and dimension keys are evaluated and invalid formulas are rejected
+func TestEvaluateDimensionFormula(t *testing.T) {
+	Values := map[string]float64{"effort": 5, "risk": 2, "complexity_2": 3}
+
+	Result, err := evaluateDimensionFormula("effort + risk * (complexity_2 - 1) / 2", Values)
+	if err != nil {
+		t.Fatalf(`expected no error, got %v`, err)
+	}
+	if Result != 7 {
+		t.Fatalf(`expected Result: %v to match 7`, Result)
+	}
+
+	Result, err = evaluateDimensionFormula("-effort * 0.5", Values)
+	if err != nil || Result != -2.5 {
+		t.Fatalf(`expected Result: %v to match -2.5 with no error, got %v`, Result, err)
+	}
+
+	for _, Formula := range []string{"", "effort +", "(effort", "effort risk", "size * 2", "1..2"} {
+		if _, err := evaluateDimensionFormula(Formula, Values); err == nil {
+			t.Fatalf(`expected Formula: %q to be invalid`, Formula)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+const battleTemplateSelect = `SELECT
+		bt.id, bt.name, COALESCE(bt.user_id::TEXT, ''), COALESCE(bt.team_id::TEXT, ''),
+		COALESCE(bt.battle_name, ''), bt.point_values_allowed, COALESCE(bt.estimation_scale_id::TEXT, ''),
+		bt.auto_finish_voting, bt.point_average_rounding, bt.voting_timer_seconds, bt.anonymous_voting,
+		bt.voting_mode, bt.dimensions, bt.dimension_formula, bt.leaders, bt.created_date, bt.updated_date
+		FROM battle_template bt`
+
+// scanBattleTemplate scans a battle template row selected by battleTemplateSelect
+func (d *Database) scanBattleTemplate(row interface{ Scan(...interface{}) error }) (*model.BattleTemplate, error) {
+	var t = &model.BattleTemplate{
+		PointValuesAllowed: make([]string, 0),
+		Dimensions:         make([]*model.BattleDimension, 0),
+		Leaders:            make([]string, 0),
+	}
+	var pointValues string
+	var dimensions string
+	var leaders string
+
+	if err := row.Scan(
+		&t.Id,
+		&t.Name,
+		&t.UserId,
+		&t.TeamId,
+		&t.BattleName,
+		&pointValues,
+		&t.EstimationScaleID,
+		&t.AutoFinishVoting,
+		&t.PointAverageRounding,
+		&t.VotingTimerSeconds,
+		&t.AnonymousVoting,
+		&t.VotingMode,
+		&dimensions,
+		&t.DimensionFormula,
+		&leaders,
+		&t.CreatedDate,
+		&t.UpdatedDate,
+	); err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal([]byte(pointValues), &t.PointValuesAllowed)
+	_ = json.Unmarshal([]byte(dimensions), &t.Dimensions)
+	_ = json.Unmarshal([]byte(leaders), &t.Leaders)
+
+	return t, nil
+}
+
+// battleTemplateList gets a list of battle templates matching the where clause
+func (d *Database) battleTemplateList(Where string, Args ...interface{}) ([]*model.BattleTemplate, error) {
+	var Templates = make([]*model.BattleTemplate, 0)
+
+	rows, err := d.db.Query(battleTemplateSelect+` `+Where+` ORDER BY bt.name;`, Args...)
+	if err != nil {
+		d.logger.Error("get battle templates query error", zap.Error(err))
+		return nil, errors.New("error getting battle templates")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := d.scanBattleTemplate(rows)
+		if err != nil {
+			d.logger.Error("battle template row scan error", zap.Error(err))
+			continue
+		}
+		Templates = append(Templates, t)
+	}
+
+	return Templates, nil
+}
+
+// BattleTemplateListByUser gets a list of the users personal battle templates
+func (d *Database) BattleTemplateListByUser(UserID string) ([]*model.BattleTemplate, error) {
+	return d.battleTemplateList(`WHERE bt.user_id = $1 AND bt.team_id IS NULL`, UserID)
+}
+
+// BattleTemplateListByTeam gets a list of the teams battle templates
+func (d *Database) BattleTemplateListByTeam(TeamID string) ([]*model.BattleTemplate, error) {
+	return d.battleTemplateList(`WHERE bt.team_id = $1`, TeamID)
+}
+
+// BattleTemplateGet gets a battle template by ID
+func (d *Database) BattleTemplateGet(TemplateID string) (*model.BattleTemplate, error) {
+	t, err := d.scanBattleTemplate(d.db.QueryRow(battleTemplateSelect+` WHERE bt.id = $1;`, TemplateID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get battle template query error", zap.Error(err))
+		}
+		return nil, errors.New("BATTLE_TEMPLATE_NOT_FOUND")
+	}
+
+	return t, nil
+}
+
+// BattleTemplateCreate creates a battle template owned by either a user or a team
+func (d *Database) BattleTemplateCreate(
+	UserID string, TeamID string, Name string,
+	BattleName string, PointValuesAllowed []string, EstimationScaleID string,
+	AutoFinishVoting bool, PointAverageRounding string, VotingTimerSeconds int, AnonymousVoting bool,
+	VotingMode string, Dimensions []*model.BattleDimension, DimensionFormula string,
+	Leaders []string,
+) (*model.BattleTemplate, error) {
+	var TemplateID string
+	var pointValuesJSON, _ = json.Marshal(PointValuesAllowed)
+	var dimensionsJSON, _ = json.Marshal(Dimensions)
+	var leadersJSON, _ = json.Marshal(Leaders)
+
+	if err := d.db.QueryRow(
+		`INSERT INTO battle_template
+		(user_id, team_id, name, battle_name, point_values_allowed, estimation_scale_id,
+		auto_finish_voting, point_average_rounding, voting_timer_seconds, anonymous_voting,
+		voting_mode, dimensions, dimension_formula, leaders)
+		VALUES (NULLIF($1, '')::UUID, NULLIF($2, '')::UUID, $3, $4, $5, NULLIF($6, '')::UUID, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id;`,
+		UserID,
+		TeamID,
+		Name,
+		BattleName,
+		string(pointValuesJSON),
+		EstimationScaleID,
+		AutoFinishVoting,
+		PointAverageRounding,
+		VotingTimerSeconds,
+		AnonymousVoting,
+		VotingMode,
+		string(dimensionsJSON),
+		DimensionFormula,
+		string(leadersJSON),
+	).Scan(&TemplateID); err != nil {
+		d.logger.Error("create battle template query error", zap.Error(err))
+		return nil, errors.New("error creating battle template")
+	}
+
+	return d.BattleTemplateGet(TemplateID)
+}
+
+// BattleTemplateDelete deletes a battle template
+func (d *Database) BattleTemplateDelete(TemplateID string) error {
+	if _, err := d.db.Exec(
+		`DELETE FROM battle_template WHERE id = $1;`,
+		TemplateID,
+	); err != nil {
+		d.logger.Error("delete battle template query error", zap.Error(err))
+		return errors.New("error deleting battle template")
+	}
+
+	return nil
+}
+
+// ConfirmBattleTemplateAccess confirms the user owns the battle template or is a member of the team that does
+func (d *Database) ConfirmBattleTemplateAccess(TemplateID string, UserID string) error {
+	var templateId string
+
+	if err := d.db.QueryRow(`SELECT bt.id
+		FROM battle_template bt
+		LEFT JOIN team_user tu ON tu.team_id = bt.team_id AND tu.user_id = $2
+		WHERE bt.id = $1 AND ((bt.team_id IS NULL AND bt.user_id = $2) OR tu.user_id IS NOT NULL);`,
+		TemplateID,
+		UserID,
+	).Scan(&templateId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm battle template access query error", zap.Error(err))
+		}
+		return errors.New("BATTLE_TEMPLATE_NOT_FOUND")
+	}
+
+	return nil
+}
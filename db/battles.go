@@ -10,8 +10,15 @@ import (
 	"go.uber.org/zap"
 )
 
-//CreateBattle creates a new story pointing session (battle)
-func (d *Database) CreateBattle(LeaderID string, BattleName string, PointValuesAllowed []string, Plans []*model.Plan, AutoFinishVoting bool, PointAverageRounding string) (*model.Battle, error) {
+// CreateBattle creates a new story pointing session (battle)
+func (d *Database) CreateBattle(LeaderID string, BattleName string, PointValuesAllowed []string, Plans []*model.Plan, AutoFinishVoting bool, PointAverageRounding string, VotingTimerSeconds int, EstimationScaleID string, AnonymousVoting bool, VotingMode string) (*model.Battle, error) {
+	if EstimationScaleID != "" {
+		Scale, err := d.EstimationScaleGet(EstimationScaleID)
+		if err != nil {
+			return nil, err
+		}
+		PointValuesAllowed = estimationScalePointValues(Scale)
+	}
 	var pointValuesJSON, _ = json.Marshal(PointValuesAllowed)
 
 	var b = &model.Battle{
@@ -21,28 +28,38 @@ func (d *Database) CreateBattle(LeaderID string, BattleName string, PointValuesA
 		VotingLocked:       true,
 		PointValuesAllowed: PointValuesAllowed,
 		AutoFinishVoting:   AutoFinishVoting,
+		VotingTimerSeconds: VotingTimerSeconds,
+		EstimationScaleID:  EstimationScaleID,
+		AnonymousVoting:    AnonymousVoting,
+		VotingMode:         VotingMode,
+		Dimensions:         make([]*model.BattleDimension, 0),
+		Breakouts:          make([]*model.BattleBreakout, 0),
 		Leaders:            make([]string, 0),
 	}
 	b.Leaders = append(b.Leaders, LeaderID)
 
 	e := d.db.QueryRow(
-		`SELECT battleId FROM create_battle($1, $2, $3, $4, $5);`,
+		`SELECT battleId FROM create_battle($1, $2, $3, $4, $5, $6, NULLIF($7, '')::UUID, $8, $9);`,
 		LeaderID,
 		BattleName,
 		string(pointValuesJSON),
 		AutoFinishVoting,
 		PointAverageRounding,
+		VotingTimerSeconds,
+		EstimationScaleID,
+		AnonymousVoting,
+		VotingMode,
 	).Scan(&b.Id)
 	if e != nil {
 		d.logger.Error("create_battle query error", zap.Error(e))
 		return nil, errors.New("error creating battle")
 	}
 
-	for _, plan := range Plans {
+	for i, plan := range Plans {
 		plan.Votes = make([]*model.Vote, 0)
 
 		e := d.db.QueryRow(
-			`INSERT INTO plans (battle_id, name, type, reference_id, link, description, acceptance_criteria) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			`INSERT INTO plans (battle_id, name, type, reference_id, link, description, acceptance_criteria, sort_order) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
 			b.Id,
 			plan.Name,
 			plan.Type,
@@ -50,6 +67,7 @@ func (d *Database) CreateBattle(LeaderID string, BattleName string, PointValuesA
 			plan.Link,
 			plan.Description,
 			plan.AcceptanceCriteria,
+			i+1,
 		).Scan(&plan.Id)
 		if e != nil {
 			d.logger.Error("insert plans error", zap.Error(e))
@@ -61,8 +79,26 @@ func (d *Database) CreateBattle(LeaderID string, BattleName string, PointValuesA
 	return b, nil
 }
 
-// ReviseBattle updates the battle by ID
-func (d *Database) ReviseBattle(BattleID string, BattleName string, PointValuesAllowed []string, AutoFinishVoting bool, PointAverageRounding string, JoinCode string, LeaderCode string) error {
+// ReviseBattle updates the battle by ID, a nil voting timer or estimation scale keeps the battles current one
+// and an empty estimation scale removes it
+func (d *Database) ReviseBattle(BattleID string, BattleName string, PointValuesAllowed []string, AutoFinishVoting bool, PointAverageRounding string, VotingTimerSeconds *int, EstimationScaleID *string, JoinCode string, LeaderCode string) error {
+	var ScaleID string
+	if EstimationScaleID != nil {
+		ScaleID = *EstimationScaleID
+	} else {
+		CurrentScaleID, err := d.GetBattleEstimationScaleID(BattleID)
+		if err != nil {
+			return err
+		}
+		ScaleID = CurrentScaleID
+	}
+	if ScaleID != "" {
+		Scale, err := d.EstimationScaleGet(ScaleID)
+		if err != nil {
+			return err
+		}
+		PointValuesAllowed = estimationScalePointValues(Scale)
+	}
 	var pointValuesJSON, _ = json.Marshal(PointValuesAllowed)
 	var encryptedJoinCode string
 	var encryptedLeaderCode string
@@ -85,9 +121,9 @@ func (d *Database) ReviseBattle(BattleID string, BattleName string, PointValuesA
 
 	if _, err := d.db.Exec(`
 		UPDATE battles
-		SET name = $2, point_values_allowed = $3, auto_finish_voting = $4, point_average_rounding = $5, join_code = $6, leader_code = $7, updated_date = NOW()
+		SET name = $2, point_values_allowed = $3, auto_finish_voting = $4, point_average_rounding = $5, voting_timer_seconds = COALESCE($6, voting_timer_seconds), estimation_scale_id = NULLIF(COALESCE($7, estimation_scale_id::TEXT), '')::UUID, join_code = $8, leader_code = $9, updated_date = NOW()
 		WHERE id = $1`,
-		BattleID, BattleName, string(pointValuesJSON), AutoFinishVoting, PointAverageRounding, encryptedJoinCode, encryptedLeaderCode,
+		BattleID, BattleName, string(pointValuesJSON), AutoFinishVoting, PointAverageRounding, VotingTimerSeconds, EstimationScaleID, encryptedJoinCode, encryptedLeaderCode,
 	); err != nil {
 		d.logger.Error("update battle error", zap.Error(err))
 		return errors.New("unable to revise battle")
@@ -96,6 +132,147 @@ func (d *Database) ReviseBattle(BattleID string, BattleName string, PointValuesA
 	return nil
 }
 
+// GetBattleVotingTimer retrieves the battle voting timer duration in seconds
+func (d *Database) GetBattleVotingTimer(BattleID string) (int, error) {
+	var VotingTimerSeconds int
+
+	if err := d.db.QueryRow(`
+		SELECT voting_timer_seconds FROM battles
+		WHERE id = $1`,
+		BattleID,
+	).Scan(&VotingTimerSeconds); err != nil {
+		d.logger.Error("get battle voting timer error", zap.Error(err))
+		return 0, errors.New("unable to retrieve battle voting timer")
+	}
+
+	return VotingTimerSeconds, nil
+}
+
+// GetBattleEstimationScaleID retrieves the ID of the battles estimation scale, empty when it has none
+func (d *Database) GetBattleEstimationScaleID(BattleID string) (string, error) {
+	var EstimationScaleID string
+
+	if err := d.db.QueryRow(`
+		SELECT COALESCE(estimation_scale_id::TEXT, '') FROM battles
+		WHERE id = $1`,
+		BattleID,
+	).Scan(&EstimationScaleID); err != nil {
+		d.logger.Error("get battle estimation scale error", zap.Error(err))
+		return "", errors.New("unable to retrieve battle estimation scale")
+	}
+
+	return EstimationScaleID, nil
+}
+
+// GetBattlePointValuesAllowed retrieves the list of values users are allowed to vote with in the battle
+func (d *Database) GetBattlePointValuesAllowed(BattleID string) ([]string, error) {
+	var PointValuesAllowed = make([]string, 0)
+	var pointValues string
+
+	if err := d.db.QueryRow(`
+		SELECT point_values_allowed FROM battles
+		WHERE id = $1`,
+		BattleID,
+	).Scan(&pointValues); err != nil {
+		d.logger.Error("get battle point values allowed error", zap.Error(err))
+		return nil, errors.New("unable to retrieve battle point values allowed")
+	}
+
+	if err := json.Unmarshal([]byte(pointValues), &PointValuesAllowed); err != nil {
+		d.logger.Error("battle point values allowed json error", zap.Error(err))
+	}
+
+	return PointValuesAllowed, nil
+}
+
+// GetBattleVotingMode retrieves the battle voting mode, either live or async
+func (d *Database) GetBattleVotingMode(BattleID string) (string, error) {
+	var VotingMode string
+
+	if err := d.db.QueryRow(`
+		SELECT voting_mode FROM battles
+		WHERE id = $1`,
+		BattleID,
+	).Scan(&VotingMode); err != nil {
+		d.logger.Error("get battle voting mode error", zap.Error(err))
+		return "", errors.New("unable to retrieve battle voting mode")
+	}
+
+	return VotingMode, nil
+}
+
+// GetExpiredAsyncBattles gets the IDs of async voting battles whose voting deadline has passed with plans still active
+func (d *Database) GetExpiredAsyncBattles() ([]string, error) {
+	var BattleIDs = make([]string, 0)
+
+	rows, err := d.db.Query(`
+		SELECT b.id FROM battles b
+		WHERE b.voting_mode = 'async' AND b.voting_deadline <= NOW()
+		AND EXISTS (SELECT 1 FROM plans p WHERE p.battle_id = b.id AND p.active = true);`,
+	)
+	if err != nil {
+		d.logger.Error("get expired async battles query error", zap.Error(err))
+		return nil, errors.New("unable to retrieve expired async battles")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var BattleID string
+		if err := rows.Scan(&BattleID); err != nil {
+			d.logger.Error("get expired async battles scan error", zap.Error(err))
+			continue
+		}
+		BattleIDs = append(BattleIDs, BattleID)
+	}
+
+	return BattleIDs, nil
+}
+
+// ConfirmBattleUser confirms the user has joined the battle and not abandoned it
+func (d *Database) ConfirmBattleUser(BattleID string, UserID string) error {
+	var userId string
+
+	if err := d.db.QueryRow(`
+		SELECT user_id FROM battles_users
+		WHERE battle_id = $1 AND user_id = $2 AND abandoned = false;`,
+		BattleID,
+		UserID,
+	).Scan(&userId); err != nil {
+		return errors.New("REQUIRES_BATTLE_USER")
+	}
+
+	return nil
+}
+
+// GetBattleLeaderUsers gets the battles leaders that have an email address and notifications enabled
+func (d *Database) GetBattleLeaderUsers(BattleID string) []*model.User {
+	var users = make([]*model.User, 0)
+
+	rows, err := d.db.Query(`
+		SELECT u.id, u.name, u.email
+		FROM battles_leaders bl
+		JOIN users u ON u.id = bl.user_id
+		WHERE bl.battle_id = $1 AND u.email IS NOT NULL AND u.email != '' AND u.notifications_enabled = true;`,
+		BattleID,
+	)
+	if err != nil {
+		d.logger.Error("get battle leader users query error", zap.Error(err))
+		return users
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(&u.Id, &u.Name, &u.Email); err != nil {
+			d.logger.Error("get battle leader users scan error", zap.Error(err))
+			continue
+		}
+		users = append(users, &u)
+	}
+
+	return users
+}
+
 // GetBattleLeaderCode retrieve the battle leader_code
 func (d *Database) GetBattleLeaderCode(BattleID string) (string, error) {
 	var EncryptedLeaderCode string
@@ -127,17 +304,19 @@ func (d *Database) GetBattle(BattleID string, UserID string) (*model.Battle, err
 		PointValuesAllowed: make([]string, 0),
 		AutoFinishVoting:   true,
 		Leaders:            make([]string, 0),
+		Dimensions:         make([]*model.BattleDimension, 0),
 	}
 
 	// get battle
 	var ActivePlanID sql.NullString
 	var pv string
 	var leaders string
+	var dimensions string
 	var JoinCode string
 	var LeaderCode string
 	e := d.db.QueryRow(
 		`
-		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, COALESCE(b.join_code, ''), COALESCE(b.leader_code, ''), b.created_date, b.updated_date,
+		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.voting_timer_seconds, COALESCE(b.estimation_scale_id::TEXT, ''), b.anonymous_voting, b.voting_mode, b.voting_deadline, b.dimensions, b.dimension_formula, COALESCE(b.join_code, ''), COALESCE(b.leader_code, ''), b.created_date, b.updated_date,
 		CASE WHEN COUNT(bl) = 0 THEN '[]'::json ELSE array_to_json(array_agg(bl.user_id)) END AS leaders
 		FROM battles b
 		LEFT JOIN battles_leaders bl ON b.id = bl.battle_id
@@ -152,6 +331,13 @@ func (d *Database) GetBattle(BattleID string, UserID string) (*model.Battle, err
 		&pv,
 		&b.AutoFinishVoting,
 		&b.PointAverageRounding,
+		&b.VotingTimerSeconds,
+		&b.EstimationScaleID,
+		&b.AnonymousVoting,
+		&b.VotingMode,
+		&b.VotingDeadline,
+		&dimensions,
+		&b.DimensionFormula,
 		&JoinCode,
 		&LeaderCode,
 		&b.CreatedDate,
@@ -165,6 +351,7 @@ func (d *Database) GetBattle(BattleID string, UserID string) (*model.Battle, err
 
 	_ = json.Unmarshal([]byte(leaders), &b.Leaders)
 	_ = json.Unmarshal([]byte(pv), &b.PointValuesAllowed)
+	_ = json.Unmarshal([]byte(dimensions), &b.Dimensions)
 	b.ActivePlanID = ActivePlanID.String
 
 	isBattleLeader := contains(b.Leaders, UserID)
@@ -187,6 +374,7 @@ func (d *Database) GetBattle(BattleID string, UserID string) (*model.Battle, err
 
 	b.Users = d.GetBattleUsers(BattleID)
 	b.Plans = d.GetPlans(BattleID, UserID)
+	b.Breakouts = d.GetBattleBreakouts(BattleID)
 
 	return b, nil
 }
@@ -208,7 +396,7 @@ func (d *Database) GetBattlesByUser(UserID string, Limit int, Offset int) ([]*mo
 	}
 
 	battleRows, battlesErr := d.db.Query(`
-		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.created_date, b.updated_date,
+		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.voting_timer_seconds, COALESCE(b.estimation_scale_id::TEXT, ''), b.anonymous_voting, b.voting_mode, b.voting_deadline, b.created_date, b.updated_date,
 		CASE WHEN COUNT(p) = 0 THEN '[]'::json ELSE array_to_json(array_agg(row_to_json(p))) END AS plans,
 		CASE WHEN COUNT(bl) = 0 THEN '[]'::json ELSE array_to_json(array_agg(bl.user_id)) END AS leaders
 		FROM battles b
@@ -245,6 +433,11 @@ func (d *Database) GetBattlesByUser(UserID string, Limit int, Offset int) ([]*mo
 			&pv,
 			&b.AutoFinishVoting,
 			&b.PointAverageRounding,
+			&b.VotingTimerSeconds,
+			&b.EstimationScaleID,
+			&b.AnonymousVoting,
+			&b.VotingMode,
+			&b.VotingDeadline,
 			&b.CreatedDate,
 			&b.UpdatedDate,
 			&plans,
@@ -304,7 +497,7 @@ func (d *Database) GetBattleUsers(BattleID string) []*model.BattleUser {
 	var users = make([]*model.BattleUser, 0)
 	rows, err := d.db.Query(
 		`SELECT
-			w.id, w.name, w.type, w.avatar, bw.active, bw.spectator, COALESCE(w.email, '')
+			w.id, w.name, w.type, w.avatar, bw.active, bw.spectator, COALESCE(w.email, ''), COALESCE(bw.breakout_id::TEXT, '')
 		FROM battles_users bw
 		LEFT JOIN users w ON bw.user_id = w.id
 		WHERE bw.battle_id = $1
@@ -315,7 +508,7 @@ func (d *Database) GetBattleUsers(BattleID string) []*model.BattleUser {
 		defer rows.Close()
 		for rows.Next() {
 			var w model.BattleUser
-			if err := rows.Scan(&w.Id, &w.Name, &w.Type, &w.Avatar, &w.Active, &w.Spectator, &w.GravatarHash); err != nil {
+			if err := rows.Scan(&w.Id, &w.Name, &w.Type, &w.Avatar, &w.Active, &w.Spectator, &w.GravatarHash, &w.BreakoutId); err != nil {
 				d.logger.Error("error getting battle users", zap.Error(err))
 			} else {
 				if w.GravatarHash != "" {
@@ -336,7 +529,7 @@ func (d *Database) GetBattleActiveUsers(BattleID string) []*model.BattleUser {
 	var users = make([]*model.BattleUser, 0)
 	rows, err := d.db.Query(
 		`SELECT
-			w.id, w.name, w.type, w.avatar, bw.active, bw.spectator, COALESCE(w.email, '')
+			w.id, w.name, w.type, w.avatar, bw.active, bw.spectator, COALESCE(w.email, ''), COALESCE(bw.breakout_id::TEXT, '')
 		FROM battles_users bw
 		LEFT JOIN users w ON bw.user_id = w.id
 		WHERE bw.battle_id = $1 AND bw.active = true
@@ -347,7 +540,7 @@ func (d *Database) GetBattleActiveUsers(BattleID string) []*model.BattleUser {
 		defer rows.Close()
 		for rows.Next() {
 			var w model.BattleUser
-			if err := rows.Scan(&w.Id, &w.Name, &w.Type, &w.Avatar, &w.Active, &w.Spectator, &w.GravatarHash); err != nil {
+			if err := rows.Scan(&w.Id, &w.Name, &w.Type, &w.Avatar, &w.Active, &w.Spectator, &w.GravatarHash, &w.BreakoutId); err != nil {
 				d.logger.Error("error getting active battle users", zap.Error(err))
 			} else {
 				if w.GravatarHash != "" {
@@ -368,7 +561,8 @@ func (d *Database) AddUserToBattle(BattleID string, UserID string) ([]*model.Bat
 	if _, err := d.db.Exec(
 		`INSERT INTO battles_users (battle_id, user_id, active)
 		VALUES ($1, $2, true)
-		ON CONFLICT (battle_id, user_id) DO UPDATE SET active = true, abandoned = false`,
+		ON CONFLICT (battle_id, user_id) DO UPDATE SET active = true, abandoned = false,
+			joined_date = CASE WHEN battles_users.active THEN battles_users.joined_date ELSE NOW() END`,
 		BattleID,
 		UserID,
 	); err != nil {
@@ -449,6 +643,43 @@ func (d *Database) SetBattleLeader(BattleID string, LeaderID string) ([]string,
 	return leaders, nil
 }
 
+// GetBattleActiveLeaderCount gets the number of the battles leaders currently connected
+func (d *Database) GetBattleActiveLeaderCount(BattleID string) (int, error) {
+	var count int
+
+	if err := d.db.QueryRow(`SELECT COUNT(*)
+		FROM battles_leaders bl
+		JOIN battles_users bu ON bu.battle_id = bl.battle_id AND bu.user_id = bl.user_id
+		WHERE bl.battle_id = $1 AND bu.active = true;`,
+		BattleID,
+	).Scan(&count); err != nil {
+		d.logger.Error("get battle active leader count query error", zap.Error(err))
+		return 0, errors.New("error getting battle active leaders")
+	}
+
+	return count, nil
+}
+
+// GetBattleFailoverUser gets the longest connected participant of the battle to promote when no leaders remain
+func (d *Database) GetBattleFailoverUser(BattleID string) (string, error) {
+	var UserID string
+
+	if err := d.db.QueryRow(`SELECT user_id
+		FROM battles_users
+		WHERE battle_id = $1 AND active = true AND spectator = false AND abandoned = false
+		ORDER BY joined_date ASC
+		LIMIT 1;`,
+		BattleID,
+	).Scan(&UserID); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get battle failover user query error", zap.Error(err))
+		}
+		return "", errors.New("NO_FAILOVER_USER")
+	}
+
+	return UserID, nil
+}
+
 // DemoteBattleLeader removes a user from battle leaders
 func (d *Database) DemoteBattleLeader(BattleID string, LeaderID string) ([]string, error) {
 	leaders := make([]string, 0)
@@ -545,7 +776,7 @@ func (d *Database) GetBattles(Limit int, Offset int) ([]*model.Battle, int, erro
 	}
 
 	battleRows, battlesErr := d.db.Query(`
-		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.created_date, b.updated_date,
+		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.voting_timer_seconds, COALESCE(b.estimation_scale_id::TEXT, ''), b.anonymous_voting, b.voting_mode, b.voting_deadline, b.created_date, b.updated_date,
 		CASE WHEN COUNT(bl) = 0 THEN '[]'::json ELSE array_to_json(array_agg(bl.user_id)) END AS leaders
 		FROM battles b
 		LEFT JOIN battles_leaders bl ON b.id = bl.battle_id
@@ -577,6 +808,11 @@ func (d *Database) GetBattles(Limit int, Offset int) ([]*model.Battle, int, erro
 			&pv,
 			&b.AutoFinishVoting,
 			&b.PointAverageRounding,
+			&b.VotingTimerSeconds,
+			&b.EstimationScaleID,
+			&b.AnonymousVoting,
+			&b.VotingMode,
+			&b.VotingDeadline,
 			&b.CreatedDate,
 			&b.UpdatedDate,
 			&leaders,
@@ -608,7 +844,7 @@ func (d *Database) GetActiveBattles(Limit int, Offset int) ([]*model.Battle, int
 	}
 
 	battleRows, battlesErr := d.db.Query(`
-		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.created_date, b.updated_date,
+		SELECT b.id, b.name, b.voting_locked, b.active_plan_id, b.point_values_allowed, b.auto_finish_voting, b.point_average_rounding, b.voting_timer_seconds, COALESCE(b.estimation_scale_id::TEXT, ''), b.anonymous_voting, b.voting_mode, b.voting_deadline, b.created_date, b.updated_date,
 		CASE WHEN COUNT(bl) = 0 THEN '[]'::json ELSE array_to_json(array_agg(bl.user_id)) END AS leaders
 		FROM battles_users bu
 		LEFT JOIN battles b ON b.id = bu.battle_id
@@ -641,6 +877,11 @@ func (d *Database) GetActiveBattles(Limit int, Offset int) ([]*model.Battle, int
 			&pv,
 			&b.AutoFinishVoting,
 			&b.PointAverageRounding,
+			&b.VotingTimerSeconds,
+			&b.EstimationScaleID,
+			&b.AnonymousVoting,
+			&b.VotingMode,
+			&b.VotingDeadline,
 			&b.CreatedDate,
 			&b.UpdatedDate,
 			&leaders,
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+const estimationScaleSelect = `SELECT
+		es.id, es.name, COALESCE(es.description, ''),
+		COALESCE(es.user_id::TEXT, ''), COALESCE(es.team_id::TEXT, ''),
+		es.is_default, es.created_date, es.updated_date,
+		COALESCE(
+			(SELECT json_agg(json_build_object('value', esv.value, 'weight', esv.weight) ORDER BY esv.sort_order)
+			FROM estimation_scale_value esv WHERE esv.scale_id = es.id), '[]'
+		) AS values
+		FROM estimation_scale es`
+
+// scanEstimationScale scans an estimation scale row selected by estimationScaleSelect
+func (d *Database) scanEstimationScale(row interface{ Scan(...interface{}) error }) (*model.EstimationScale, error) {
+	var s = &model.EstimationScale{
+		Values: make([]*model.EstimationScaleValue, 0),
+	}
+	var values string
+
+	if err := row.Scan(
+		&s.Id,
+		&s.Name,
+		&s.Description,
+		&s.UserId,
+		&s.TeamId,
+		&s.IsDefault,
+		&s.CreatedDate,
+		&s.UpdatedDate,
+		&values,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(values), &s.Values); err != nil {
+		d.logger.Error("estimation scale values json error", zap.Error(err))
+	}
+
+	return s, nil
+}
+
+// estimationScaleList gets a list of estimation scales matching the where clause
+func (d *Database) estimationScaleList(Where string, Args ...interface{}) ([]*model.EstimationScale, error) {
+	var Scales = make([]*model.EstimationScale, 0)
+
+	rows, err := d.db.Query(estimationScaleSelect+` `+Where+` ORDER BY es.is_default DESC, es.name;`, Args...)
+	if err != nil {
+		d.logger.Error("get estimation scales query error", zap.Error(err))
+		return nil, errors.New("error getting estimation scales")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := d.scanEstimationScale(rows)
+		if err != nil {
+			d.logger.Error("estimation scale row scan error", zap.Error(err))
+			continue
+		}
+		Scales = append(Scales, s)
+	}
+
+	return Scales, nil
+}
+
+// EstimationScaleListByUser gets a list of the users personal estimation scales
+func (d *Database) EstimationScaleListByUser(UserID string) ([]*model.EstimationScale, error) {
+	return d.estimationScaleList(`WHERE es.user_id = $1 AND es.team_id IS NULL`, UserID)
+}
+
+// EstimationScaleListByTeam gets a list of the teams estimation scales
+func (d *Database) EstimationScaleListByTeam(TeamID string) ([]*model.EstimationScale, error) {
+	return d.estimationScaleList(`WHERE es.team_id = $1`, TeamID)
+}
+
+// EstimationScaleGet gets an estimation scale by ID
+func (d *Database) EstimationScaleGet(ScaleID string) (*model.EstimationScale, error) {
+	s, err := d.scanEstimationScale(d.db.QueryRow(estimationScaleSelect+` WHERE es.id = $1;`, ScaleID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get estimation scale query error", zap.Error(err))
+		}
+		return nil, errors.New("ESTIMATION_SCALE_NOT_FOUND")
+	}
+
+	return s, nil
+}
+
+// EstimationScaleGetUserDefault gets the users default personal estimation scale
+func (d *Database) EstimationScaleGetUserDefault(UserID string) (*model.EstimationScale, error) {
+	s, err := d.scanEstimationScale(d.db.QueryRow(
+		estimationScaleSelect+` WHERE es.user_id = $1 AND es.team_id IS NULL AND es.is_default = true LIMIT 1;`,
+		UserID,
+	))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get default estimation scale query error", zap.Error(err))
+		}
+		return nil, errors.New("ESTIMATION_SCALE_NOT_FOUND")
+	}
+
+	return s, nil
+}
+
+// EstimationScaleCreate creates an estimation scale owned by either a user or a team
+func (d *Database) EstimationScaleCreate(UserID string, TeamID string, Name string, Description string, IsDefault bool, Values []*model.EstimationScaleValue) (*model.EstimationScale, error) {
+	var ScaleID string
+	var valuesJSON, _ = json.Marshal(Values)
+
+	if err := d.db.QueryRow(
+		`SELECT scaleId FROM estimation_scale_create(NULLIF($1, '')::UUID, NULLIF($2, '')::UUID, $3, $4, $5, $6);`,
+		UserID,
+		TeamID,
+		Name,
+		Description,
+		IsDefault,
+		string(valuesJSON),
+	).Scan(&ScaleID); err != nil {
+		d.logger.Error("estimation_scale_create query error", zap.Error(err))
+		return nil, errors.New("error creating estimation scale")
+	}
+
+	return d.EstimationScaleGet(ScaleID)
+}
+
+// EstimationScaleUpdate updates an estimation scale replacing its values
+func (d *Database) EstimationScaleUpdate(ScaleID string, Name string, Description string, IsDefault bool, Values []*model.EstimationScaleValue) (*model.EstimationScale, error) {
+	var valuesJSON, _ = json.Marshal(Values)
+
+	if _, err := d.db.Exec(
+		`CALL estimation_scale_update($1, $2, $3, $4, $5);`,
+		ScaleID,
+		Name,
+		Description,
+		IsDefault,
+		string(valuesJSON),
+	); err != nil {
+		d.logger.Error("estimation_scale_update query error", zap.Error(err))
+		return nil, errors.New("error updating estimation scale")
+	}
+
+	return d.EstimationScaleGet(ScaleID)
+}
+
+// EstimationScaleDelete deletes an estimation scale
+func (d *Database) EstimationScaleDelete(ScaleID string) error {
+	if _, err := d.db.Exec(
+		`DELETE FROM estimation_scale WHERE id = $1;`,
+		ScaleID,
+	); err != nil {
+		d.logger.Error("delete estimation scale query error", zap.Error(err))
+		return errors.New("error deleting estimation scale")
+	}
+
+	return nil
+}
+
+// ConfirmEstimationScaleAccess confirms the user owns the estimation scale or is a member of the team that does
+func (d *Database) ConfirmEstimationScaleAccess(ScaleID string, UserID string) error {
+	var scaleId string
+
+	if err := d.db.QueryRow(`SELECT es.id
+		FROM estimation_scale es
+		LEFT JOIN team_user tu ON tu.team_id = es.team_id AND tu.user_id = $2
+		WHERE es.id = $1 AND ((es.team_id IS NULL AND es.user_id = $2) OR tu.user_id IS NOT NULL);`,
+		ScaleID,
+		UserID,
+	).Scan(&scaleId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm estimation scale access query error", zap.Error(err))
+		}
+		return errors.New("ESTIMATION_SCALE_NOT_FOUND")
+	}
+
+	return nil
+}
+
+// estimationScalePointValues gets the ordered list of values for an estimation scale
+func estimationScalePointValues(Scale *model.EstimationScale) []string {
+	var PointValues = make([]string, 0, len(Scale.Values))
+
+	for _, v := range Scale.Values {
+		PointValues = append(PointValues, v.Value)
+	}
+
+	return PointValues
+}
@@ -0,0 +1,19 @@
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding) VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE battles DROP COLUMN voting_timer_seconds;
\ No newline at end of file
@@ -0,0 +1,21 @@
+ALTER TABLE battles ADD COLUMN voting_timer_seconds INTEGER DEFAULT 0;
+
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
\ No newline at end of file
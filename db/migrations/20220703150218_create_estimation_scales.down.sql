@@ -0,0 +1,28 @@
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER, UUID);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP PROCEDURE estimation_scale_update(UUID, VARCHAR, TEXT, BOOL, JSONB);
+DROP FUNCTION estimation_scale_create(UUID, UUID, VARCHAR, TEXT, BOOL, JSONB);
+DROP PROCEDURE estimation_scale_set_values(UUID, JSONB);
+
+ALTER TABLE battles DROP COLUMN estimation_scale_id;
+
+DROP TABLE estimation_scale_value;
+DROP TABLE estimation_scale;
\ No newline at end of file
@@ -0,0 +1,136 @@
+CREATE TABLE estimation_scale (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(256) NOT NULL,
+    description TEXT,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID REFERENCES team(id) ON DELETE CASCADE,
+    is_default BOOL DEFAULT false,
+    created_date TIMESTAMPTZ DEFAULT NOW(),
+    updated_date TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (user_id IS NOT NULL OR team_id IS NOT NULL)
+);
+
+CREATE TABLE estimation_scale_value (
+    scale_id UUID REFERENCES estimation_scale(id) ON DELETE CASCADE,
+    value VARCHAR(32) NOT NULL,
+    weight NUMERIC,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (scale_id, value)
+);
+
+ALTER TABLE battles ADD COLUMN estimation_scale_id UUID REFERENCES estimation_scale(id) ON DELETE SET NULL;
+
+-- allow vote values longer than the original 3 characters e.g. XXL, 8-16h --
+ALTER TYPE UsersVote ALTER ATTRIBUTE "vote" TYPE VARCHAR(32);
+ALTER TABLE plans ALTER COLUMN points TYPE VARCHAR(32);
+
+-- Set Estimation Scale Values, replaces any existing values keeping the given order --
+CREATE PROCEDURE estimation_scale_set_values(scaleId UUID, scaleValues JSONB)
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM estimation_scale_value WHERE scale_id = scaleId;
+    INSERT INTO estimation_scale_value (scale_id, value, weight, sort_order)
+        SELECT scaleId, sv.elem->>'value', (sv.elem->>'weight')::NUMERIC, sv.idx
+        FROM jsonb_array_elements(scaleValues) WITH ORDINALITY AS sv(elem, idx);
+END;
+$$;
+
+-- Create Estimation Scale --
+CREATE FUNCTION estimation_scale_create(
+    IN userId UUID,
+    IN teamId UUID,
+    IN scaleName VARCHAR(256),
+    IN scaleDescription TEXT,
+    IN isDefault BOOL,
+    IN scaleValues JSONB,
+    OUT scaleId UUID
+) AS $$
+BEGIN
+    IF isDefault THEN
+        UPDATE estimation_scale SET is_default = false
+        WHERE (teamId IS NULL AND team_id IS NULL AND user_id = userId) OR (teamId IS NOT NULL AND team_id = teamId);
+    END IF;
+    INSERT INTO estimation_scale (user_id, team_id, name, description, is_default)
+        VALUES (userId, teamId, scaleName, scaleDescription, isDefault) RETURNING id INTO scaleId;
+    CALL estimation_scale_set_values(scaleId, scaleValues);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Update Estimation Scale --
+CREATE PROCEDURE estimation_scale_update(scaleId UUID, scaleName VARCHAR(256), scaleDescription TEXT, isDefault BOOL, scaleValues JSONB)
+LANGUAGE plpgsql AS $$
+DECLARE userId UUID;
+DECLARE teamId UUID;
+BEGIN
+    SELECT user_id, team_id INTO userId, teamId FROM estimation_scale WHERE id = scaleId;
+    IF isDefault THEN
+        UPDATE estimation_scale SET is_default = false
+        WHERE id != scaleId AND (
+            (teamId IS NULL AND team_id IS NULL AND user_id = userId) OR (teamId IS NOT NULL AND team_id = teamId)
+        );
+    END IF;
+    UPDATE estimation_scale
+    SET name = scaleName, description = scaleDescription, is_default = isDefault, updated_date = NOW()
+    WHERE id = scaleId;
+    CALL estimation_scale_set_values(scaleId, scaleValues);
+
+    COMMIT;
+END;
+$$;
+
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    IN estimationScaleId UUID,
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds, estimation_scale_id)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds, estimationScaleId) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Set User Vote --
+CREATE OR REPLACE PROCEDURE set_user_vote(planId UUID, userId UUID, userVote VARCHAR(32))
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE plans p1
+    SET votes = (
+        SELECT json_agg(data)
+        FROM (
+            SELECT coalesce(newVote."warriorId", oldVote."warriorId") AS "warriorId", coalesce(newVote.vote, oldVote.vote) AS vote
+            FROM jsonb_populate_recordset(null::UsersVote,p1.votes) AS oldVote
+            FULL JOIN jsonb_populate_recordset(null::UsersVote,
+                jsonb_build_array(jsonb_build_object('warriorId', userId, 'vote', userVote))
+            ) AS newVote
+            ON newVote."warriorId" = oldVote."warriorId"
+        ) data
+    )
+    WHERE p1.id = planId;
+
+    UPDATE users SET last_active = NOW() WHERE id = userId;
+
+    COMMIT;
+END;
+$$;
+
+-- Finalize Plan --
+CREATE OR REPLACE PROCEDURE finalize_plan(battleId UUID, planId UUID, planPoints VARCHAR(32))
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set plan points and deactivate
+    UPDATE plans SET updated_date = NOW(), active = false, points = planPoints WHERE id = planId;
+    -- reset battle active_plan_id
+    UPDATE battles SET updated_date = NOW(), active_plan_id = null WHERE id = battleId;
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- Activate a Battles Plan, and de-activate any current active plan
+CREATE OR REPLACE PROCEDURE activate_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false WHERE battle_id = battle_id;
+    -- set PlanID active to true
+    UPDATE plans SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb WHERE id = planId;
+    -- set battle VotingLocked and ActivePlanID
+    UPDATE battles SET updated_date = NOW(), voting_locked = false, active_plan_id = planId WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+ALTER TABLE plans DROP COLUMN vote_results;
\ No newline at end of file
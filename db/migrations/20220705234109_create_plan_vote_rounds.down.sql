@@ -0,0 +1,41 @@
+DROP PROCEDURE archive_plan_vote_round(UUID);
+
+-- Activate a Battles Plan, and de-activate any current active plan
+CREATE OR REPLACE PROCEDURE activate_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false WHERE battle_id = battleId;
+    -- set PlanID active to true
+    UPDATE plans SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL WHERE id = planId;
+    -- set battle VotingLocked and ActivePlanID
+    UPDATE battles SET updated_date = NOW(), voting_locked = false, active_plan_id = planId WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- End a Battles Plan Voting --
+CREATE OR REPLACE PROCEDURE end_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, voteend_time = NOW() WHERE battle_id = battleId;
+    -- set battle VotingLocked
+    UPDATE battles SET updated_date = NOW(), voting_locked = true WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Skip a Battles Plan Voting --
+CREATE OR REPLACE PROCEDURE skip_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, skipped = true, voteend_time = NOW() WHERE battle_id = battleId;
+    -- set battle VotingLocked and activePlanId to null
+    UPDATE battles SET updated_date = NOW(), voting_locked = true, active_plan_id = null WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+DROP TABLE plan_vote_round;
\ No newline at end of file
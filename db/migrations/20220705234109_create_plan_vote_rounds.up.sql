@@ -0,0 +1,72 @@
+CREATE TABLE plan_vote_round (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id UUID REFERENCES plans(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    votes JSONB DEFAULT '[]'::jsonb,
+    results JSONB,
+    start_time TIMESTAMPTZ,
+    end_time TIMESTAMPTZ,
+    UNIQUE (plan_id, start_time)
+);
+
+-- End a Battles Plan Voting --
+CREATE OR REPLACE PROCEDURE end_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, voteend_time = NOW() WHERE id = planId;
+    -- set battle VotingLocked
+    UPDATE battles SET updated_date = NOW(), voting_locked = true WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Activate a Battles Plan, de-activating any current active plan and archiving the plans previous votes before they're reset --
+CREATE OR REPLACE PROCEDURE activate_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false, ending the voting round of those still active
+    UPDATE plans SET updated_date = NOW(), active = false,
+        voteend_time = CASE WHEN active THEN NOW() ELSE voteend_time END
+    WHERE battle_id = battleId;
+    -- archive the round being replaced so re-activating doesn't lose its votes
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    -- set PlanID active to true
+    UPDATE plans SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL WHERE id = planId;
+    -- set battle VotingLocked and ActivePlanID
+    UPDATE battles SET updated_date = NOW(), voting_locked = false, active_plan_id = planId WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Skip a Battles Plan Voting, archiving its votes --
+CREATE OR REPLACE PROCEDURE skip_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, skipped = true, voteend_time = NOW() WHERE id = planId;
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    -- set battle VotingLocked and activePlanId to null
+    UPDATE battles SET updated_date = NOW(), voting_locked = true, active_plan_id = null WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Archive the Plans current voting round, ending voting again for the same round only updates it --
+CREATE PROCEDURE archive_plan_vote_round(planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO plan_vote_round (plan_id, round, votes, results, start_time, end_time)
+        SELECT p.id, (SELECT COALESCE(MAX(pvr.round), 0) + 1 FROM plan_vote_round pvr WHERE pvr.plan_id = p.id),
+            p.votes, p.vote_results, p.votestart_time, p.voteend_time
+        FROM plans p WHERE p.id = planId
+    ON CONFLICT (plan_id, start_time) DO UPDATE
+        SET votes = EXCLUDED.votes, results = EXCLUDED.results, end_time = EXCLUDED.end_time;
+
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,39 @@
+ALTER TABLE plans ADD COLUMN sort_order INTEGER DEFAULT 0;
+
+UPDATE plans p SET sort_order = o.plan_order
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY battle_id ORDER BY created_date) AS plan_order FROM plans
+) o
+WHERE p.id = o.id;
+
+-- Create a Battle Plan, added to the end of the battles plan order --
+CREATE OR REPLACE PROCEDURE create_plan(battleId UUID, planName VARCHAR(256), planType VARCHAR(64), referenceId VARCHAR(128), planLink TEXT, planDescription TEXT, acceptanceCriteria TEXT)
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO plans (battle_id, name, type, reference_id, link, description, acceptance_criteria, sort_order)
+    VALUES (
+        battleId, planName, planType, referenceId, planLink, planDescription, acceptanceCriteria,
+        (SELECT COALESCE(MAX(sort_order), 0) + 1 FROM plans WHERE battle_id = battleId)
+    );
+
+    UPDATE battles SET updated_date = NOW() WHERE id = battleId;
+END;
+$$;
+
+-- Reorder a Battles Plans, plans not in the list keep their relative order after the listed plans --
+CREATE PROCEDURE reorder_plans(battleId UUID, planIds JSONB)
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE plans p SET sort_order = o.plan_order
+    FROM (
+        SELECT bp.id, ROW_NUMBER() OVER (ORDER BY po.idx NULLS LAST, bp.sort_order, bp.created_date) AS plan_order
+        FROM plans bp
+        LEFT JOIN jsonb_array_elements_text(planIds) WITH ORDINALITY AS po(plan_id, idx) ON po.plan_id = bp.id::TEXT
+        WHERE bp.battle_id = battleId
+    ) o
+    WHERE p.id = o.id;
+    UPDATE battles SET updated_date = NOW() WHERE id = battleId;
+
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,22 @@
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER, UUID, BOOL);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    IN estimationScaleId UUID,
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds, estimation_scale_id)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds, estimationScaleId) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE battles DROP COLUMN anonymous_voting;
\ No newline at end of file
@@ -0,0 +1,23 @@
+ALTER TABLE battles ADD COLUMN anonymous_voting BOOL DEFAULT false;
+
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER, UUID);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    IN estimationScaleId UUID,
+    IN anonymousVoting BOOL,
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds, estimation_scale_id, anonymous_voting)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds, estimationScaleId, anonymousVoting) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE battle_template;
\ No newline at end of file
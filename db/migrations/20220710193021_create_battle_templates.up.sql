@@ -0,0 +1,17 @@
+CREATE TABLE battle_template (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(256) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID REFERENCES team(id) ON DELETE CASCADE,
+    battle_name VARCHAR(256),
+    point_values_allowed JSONB DEFAULT '[]'::jsonb,
+    estimation_scale_id UUID REFERENCES estimation_scale(id) ON DELETE SET NULL,
+    auto_finish_voting BOOL DEFAULT true,
+    point_average_rounding VARCHAR(5) DEFAULT 'ceil',
+    voting_timer_seconds INTEGER DEFAULT 0,
+    anonymous_voting BOOL DEFAULT false,
+    leaders JSONB DEFAULT '[]'::jsonb,
+    created_date TIMESTAMPTZ DEFAULT NOW(),
+    updated_date TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (user_id IS NOT NULL OR team_id IS NOT NULL)
+);
\ No newline at end of file
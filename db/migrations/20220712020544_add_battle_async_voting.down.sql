@@ -0,0 +1,39 @@
+-- End a Battles Plan Voting --
+CREATE OR REPLACE PROCEDURE end_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, voteend_time = NOW() WHERE id = planId;
+    -- set battle VotingLocked
+    UPDATE battles SET updated_date = NOW(), voting_locked = true WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+DROP PROCEDURE start_async_plan_voting(UUID, JSONB, TIMESTAMPTZ);
+
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER, UUID, BOOL, VARCHAR);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    IN estimationScaleId UUID,
+    IN anonymousVoting BOOL,
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds, estimation_scale_id, anonymous_voting)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds, estimationScaleId, anonymousVoting) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE battle_template DROP COLUMN voting_mode;
+ALTER TABLE battles DROP COLUMN voting_deadline;
+ALTER TABLE battles DROP COLUMN voting_mode;
\ No newline at end of file
@@ -0,0 +1,57 @@
+ALTER TABLE battles ADD COLUMN voting_mode VARCHAR(16) DEFAULT 'live';
+ALTER TABLE battles ADD COLUMN voting_deadline TIMESTAMPTZ;
+ALTER TABLE battle_template ADD COLUMN voting_mode VARCHAR(16) NOT NULL DEFAULT 'live';
+
+DROP FUNCTION create_battle(UUID, VARCHAR, JSONB, BOOL, VARCHAR, INTEGER, UUID, BOOL);
+
+-- Create Battle --
+CREATE FUNCTION create_battle(
+    IN leaderId UUID,
+    IN battleName VARCHAR(256),
+    IN pointsAllowed JSONB,
+    IN autoVoting BOOL,
+    IN pointAverageRounding VARCHAR(5),
+    IN votingTimerSeconds INTEGER,
+    IN estimationScaleId UUID,
+    IN anonymousVoting BOOL,
+    IN votingMode VARCHAR(16),
+    OUT battleId UUID
+) AS $$
+BEGIN
+    INSERT INTO battles (owner_id, name, point_values_allowed, auto_finish_voting, point_average_rounding, voting_timer_seconds, estimation_scale_id, anonymous_voting, voting_mode)
+        VALUES (leaderId, battleName, pointsAllowed, autoVoting, pointAverageRounding, votingTimerSeconds, estimationScaleId, anonymousVoting, votingMode) RETURNING id INTO battleId;
+    INSERT INTO battles_leaders (battle_id, user_id) VALUES (battleId, leaderId);
+    INSERT INTO battles_users (battle_id, user_id) VALUES (battleId, leaderId);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Start Async Voting, activates the given plans at once until the deadline --
+CREATE PROCEDURE start_async_plan_voting(battleId UUID, planIds JSONB, votingDeadline TIMESTAMPTZ)
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE plans
+    SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL
+    WHERE battle_id = battleId AND id::TEXT IN (SELECT jsonb_array_elements_text(planIds));
+    UPDATE battles
+    SET updated_date = NOW(), voting_locked = false, active_plan_id = null, voting_deadline = votingDeadline
+    WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- End a Battles Plan Voting, voting stays unlocked while async voting plans remain active --
+CREATE OR REPLACE PROCEDURE end_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+DECLARE plansActive BOOL;
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, voteend_time = NOW() WHERE id = planId;
+    plansActive := EXISTS (SELECT 1 FROM plans WHERE battle_id = battleId AND active = true);
+    -- set battle VotingLocked
+    UPDATE battles
+    SET updated_date = NOW(), voting_locked = NOT plansActive,
+        voting_deadline = CASE WHEN plansActive THEN voting_deadline ELSE NULL END
+    WHERE id = battleId;
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS plan_comment_add(UUID, UUID, UUID, TEXT);
+DROP PROCEDURE IF EXISTS plan_comment_edit(UUID, UUID, TEXT);
+DROP PROCEDURE IF EXISTS plan_comment_delete(UUID, UUID);
+DROP TABLE IF EXISTS plan_comment;
\ No newline at end of file
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS plan_comment (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    battle_id UUID REFERENCES battles(id) ON DELETE CASCADE,
+    plan_id UUID REFERENCES plans(id) ON DELETE CASCADE,
+    comment TEXT,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_date TIMESTAMPTZ DEFAULT NOW(),
+    updated_date TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS plan_comment_plan_id_idx ON plan_comment (plan_id);
+
+-- Add a Battle Plan comment, returning false when the plan isn't in the battle --
+CREATE FUNCTION plan_comment_add(battleId UUID, planId UUID, userId UUID, comment TEXT) RETURNS BOOL
+AS $$
+BEGIN
+    INSERT INTO plan_comment (battle_id, plan_id, user_id, comment)
+        SELECT p.battle_id, p.id, userId, comment FROM plans p WHERE p.id = planId AND p.battle_id = battleId;
+    IF NOT FOUND THEN
+        RETURN false;
+    END IF;
+    UPDATE battles SET updated_date = NOW() WHERE id = battleId;
+
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Edit a Battle Plan comment --
+CREATE PROCEDURE plan_comment_edit(battleId UUID, commentId UUID, updatedComment TEXT)
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE plan_comment SET comment = updatedComment, updated_date = NOW()
+        WHERE id = commentId AND battle_id = battleId;
+    UPDATE battles SET updated_date = NOW() WHERE id = battleId;
+
+    COMMIT;
+END;
+$$;
+
+-- Delete a Battle Plan comment --
+CREATE PROCEDURE plan_comment_delete(battleId UUID, commentId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM plan_comment WHERE id = commentId AND battle_id = battleId;
+    UPDATE battles SET updated_date = NOW() WHERE id = battleId;
+
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,87 @@
+-- Start Async Voting, activates the given plans at once until the deadline --
+CREATE OR REPLACE PROCEDURE start_async_plan_voting(battleId UUID, planIds JSONB, votingDeadline TIMESTAMPTZ)
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE plans
+    SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL
+    WHERE battle_id = battleId AND id::TEXT IN (SELECT jsonb_array_elements_text(planIds));
+    UPDATE battles
+    SET updated_date = NOW(), voting_locked = false, active_plan_id = null, voting_deadline = votingDeadline
+    WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Activate a Battles Plan, and de-activate any current active plan
+CREATE OR REPLACE PROCEDURE activate_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false, ending the voting round of those still active
+    UPDATE plans SET updated_date = NOW(), active = false,
+        voteend_time = CASE WHEN active THEN NOW() ELSE voteend_time END
+    WHERE battle_id = battleId;
+    -- archive the round being replaced so re-activating doesn't lose its votes
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    -- set PlanID active to true
+    UPDATE plans SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL WHERE id = planId;
+    -- set battle VotingLocked and ActivePlanID
+    UPDATE battles SET updated_date = NOW(), voting_locked = false, active_plan_id = planId WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Retract User Vote --
+CREATE OR REPLACE PROCEDURE retract_user_vote(planId UUID, userId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE plans p1
+    SET votes = (
+        SELECT coalesce(json_agg(data), '[]'::JSON)
+        FROM (
+            SELECT coalesce(oldVote."warriorId") AS "warriorId", coalesce(oldVote.vote) AS vote
+            FROM jsonb_populate_recordset(null::UsersVote,p1.votes) AS oldVote
+            WHERE oldVote."warriorId" != userId
+        ) data
+    )
+    WHERE p1.id = planId;
+
+    UPDATE users SET last_active = NOW() WHERE id = userId;
+
+    COMMIT;
+END;
+$$;
+
+-- Set User Vote --
+DROP PROCEDURE set_user_vote(UUID, UUID, VARCHAR, VARCHAR);
+CREATE PROCEDURE set_user_vote(planId UUID, userId UUID, userVote VARCHAR(32))
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE plans p1
+    SET votes = (
+        SELECT json_agg(data)
+        FROM (
+            SELECT coalesce(newVote."warriorId", oldVote."warriorId") AS "warriorId", coalesce(newVote.vote, oldVote.vote) AS vote
+            FROM jsonb_populate_recordset(null::UsersVote,p1.votes) AS oldVote
+            FULL JOIN jsonb_populate_recordset(null::UsersVote,
+                jsonb_build_array(jsonb_build_object('warriorId', userId, 'vote', userVote))
+            ) AS newVote
+            ON newVote."warriorId" = oldVote."warriorId"
+        ) data
+    )
+    WHERE p1.id = planId;
+
+    UPDATE users SET last_active = NOW() WHERE id = userId;
+
+    COMMIT;
+END;
+$$;
+
+ALTER TABLE plans DROP COLUMN dimension_results;
+ALTER TABLE plans DROP COLUMN dimension_points;
+ALTER TABLE battle_template DROP COLUMN dimension_formula;
+ALTER TABLE battle_template DROP COLUMN dimensions;
+ALTER TABLE battles DROP COLUMN dimension_formula;
+ALTER TABLE battles DROP COLUMN dimensions;
+ALTER TYPE UsersVote DROP ATTRIBUTE "dimension";
\ No newline at end of file
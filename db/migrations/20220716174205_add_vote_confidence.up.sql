@@ -0,0 +1,52 @@
+ALTER TYPE UsersVote ADD ATTRIBUTE "confidence" SMALLINT;
+
+-- Set User Vote, a user has one vote per plan estimation dimension with an optional confidence level --
+DROP PROCEDURE set_user_vote(UUID, UUID, VARCHAR, VARCHAR);
+CREATE PROCEDURE set_user_vote(planId UUID, userId UUID, userVote VARCHAR(32), voteDimension VARCHAR(64), voteConfidence SMALLINT)
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE plans p1
+    SET votes = (
+        SELECT json_agg(data)
+        FROM (
+            SELECT coalesce(newVote."warriorId", oldVote."warriorId") AS "warriorId", coalesce(newVote.vote, oldVote.vote) AS vote,
+                coalesce(newVote.dimension, oldVote.dimension) AS dimension,
+                CASE WHEN newVote."warriorId" IS NULL THEN oldVote.confidence ELSE newVote.confidence END AS confidence
+            FROM jsonb_populate_recordset(null::UsersVote,p1.votes) AS oldVote
+            FULL JOIN jsonb_populate_recordset(null::UsersVote,
+                jsonb_build_array(jsonb_build_object(
+                    'warriorId', userId, 'vote', userVote, 'dimension', NULLIF(voteDimension, ''), 'confidence', voteConfidence
+                ))
+            ) AS newVote
+            ON newVote."warriorId" = oldVote."warriorId" AND newVote.dimension IS NOT DISTINCT FROM oldVote.dimension
+        ) data
+    )
+    WHERE p1.id = planId;
+
+    UPDATE users SET last_active = NOW() WHERE id = userId;
+
+    COMMIT;
+END;
+$$;
+
+-- Retract User Vote, removes the users votes for every dimension --
+CREATE OR REPLACE PROCEDURE retract_user_vote(planId UUID, userId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE plans p1
+    SET votes = (
+        SELECT coalesce(json_agg(data), '[]'::JSON)
+        FROM (
+            SELECT oldVote."warriorId" AS "warriorId", oldVote.vote AS vote, oldVote.dimension AS dimension,
+                oldVote.confidence AS confidence
+            FROM jsonb_populate_recordset(null::UsersVote,p1.votes) AS oldVote
+            WHERE oldVote."warriorId" != userId
+        ) data
+    )
+    WHERE p1.id = planId;
+
+    UPDATE users SET last_active = NOW() WHERE id = userId;
+
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS observer_token;
\ No newline at end of file
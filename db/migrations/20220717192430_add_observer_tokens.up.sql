@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS observer_token (
+    id TEXT NOT NULL PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL CHECK (entity_type IN ('battle', 'retro', 'storyboard')),
+    entity_id UUID NOT NULL,
+    name VARCHAR(256),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_date TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS observer_token_entity_idx ON observer_token (entity_type, entity_id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE battles_users DROP COLUMN IF EXISTS joined_date;
\ No newline at end of file
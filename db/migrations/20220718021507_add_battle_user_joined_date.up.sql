@@ -0,0 +1 @@
+ALTER TABLE battles_users ADD COLUMN IF NOT EXISTS joined_date TIMESTAMPTZ DEFAULT NOW();
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS plans_reference_id_idx;
+ALTER TABLE plans DROP COLUMN IF EXISTS actual_effort;
+ALTER TABLE plans DROP COLUMN IF EXISTS actual_points;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS actual_points VARCHAR(16);
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS actual_effort DOUBLE PRECISION;
+CREATE INDEX IF NOT EXISTS plans_reference_id_idx ON plans (reference_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_sprint_plan;
+DROP TABLE IF EXISTS team_sprint;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS team_sprint (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    team_id UUID NOT NULL REFERENCES team(id) ON DELETE CASCADE,
+    name VARCHAR(256) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    capacity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_date TIMESTAMPTZ DEFAULT NOW(),
+    updated_date TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+CREATE INDEX IF NOT EXISTS team_sprint_team_id_idx ON team_sprint (team_id);
+
+CREATE TABLE IF NOT EXISTS team_sprint_plan (
+    sprint_id UUID NOT NULL REFERENCES team_sprint(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+    done BOOL NOT NULL DEFAULT false,
+    done_date TIMESTAMPTZ,
+    committed_date TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (sprint_id, plan_id)
+);
\ No newline at end of file
@@ -0,0 +1,49 @@
+CREATE OR REPLACE PROCEDURE activate_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false, ending the voting round of those still active
+    UPDATE plans SET updated_date = NOW(), active = false,
+        voteend_time = CASE WHEN active THEN NOW() ELSE voteend_time END
+    WHERE battle_id = battleId;
+    -- archive the round being replaced so re-activating doesn't lose its votes
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    -- set PlanID active to true
+    UPDATE plans SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL,
+        dimension_points = '{}'::jsonb, dimension_results = NULL
+    WHERE id = planId;
+    -- set battle VotingLocked and ActivePlanID
+    UPDATE battles SET updated_date = NOW(), voting_locked = false, active_plan_id = planId WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+CREATE OR REPLACE PROCEDURE skip_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, skipped = true, voteend_time = NOW() WHERE id = planId;
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    -- set battle VotingLocked and activePlanId to null
+    UPDATE battles SET updated_date = NOW(), voting_locked = true, active_plan_id = null WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+CREATE OR REPLACE PROCEDURE finalize_plan(battleId UUID, planId UUID, planPoints VARCHAR(32))
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set plan points and deactivate
+    UPDATE plans SET updated_date = NOW(), active = false, points = planPoints WHERE id = planId;
+    -- reset battle active_plan_id
+    UPDATE battles SET updated_date = NOW(), active_plan_id = null WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+ALTER TABLE plans DROP COLUMN IF EXISTS breakout_id;
+ALTER TABLE battles_users DROP COLUMN IF EXISTS breakout_id;
+DROP TABLE IF EXISTS battle_breakout;
\ No newline at end of file
@@ -0,0 +1,75 @@
+CREATE TABLE IF NOT EXISTS battle_breakout (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    battle_id UUID NOT NULL REFERENCES battles(id) ON DELETE CASCADE,
+    name VARCHAR(256) NOT NULL,
+    leader_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    active_plan_id UUID REFERENCES plans(id) ON DELETE SET NULL,
+    created_date TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS battle_breakout_battle_id_idx ON battle_breakout (battle_id);
+ALTER TABLE battles_users ADD COLUMN IF NOT EXISTS breakout_id UUID REFERENCES battle_breakout(id) ON DELETE SET NULL;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS breakout_id UUID REFERENCES battle_breakout(id) ON DELETE SET NULL;
+
+-- Activate a plan for voting, plans in a breakout only deactivate the other plans of that breakout --
+CREATE OR REPLACE PROCEDURE activate_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+DECLARE breakoutId UUID;
+BEGIN
+    SELECT breakout_id INTO breakoutId FROM plans WHERE id = planId;
+    -- set current active to false, ending the voting round of those still active
+    UPDATE plans SET updated_date = NOW(), active = false,
+        voteend_time = CASE WHEN active THEN NOW() ELSE voteend_time END
+    WHERE battle_id = battleId AND breakout_id IS NOT DISTINCT FROM breakoutId;
+    -- archive the round being replaced so re-activating doesn't lose its votes
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    -- set PlanID active to true
+    UPDATE plans SET updated_date = NOW(), active = true, skipped = false, points = '', votestart_time = NOW(), votes = '[]'::jsonb, vote_results = NULL,
+        dimension_points = '{}'::jsonb, dimension_results = NULL
+    WHERE id = planId;
+    IF breakoutId IS NULL THEN
+        -- set battle VotingLocked and ActivePlanID
+        UPDATE battles SET updated_date = NOW(), voting_locked = false, active_plan_id = planId WHERE id = battleId;
+    ELSE
+        UPDATE battle_breakout SET active_plan_id = planId WHERE id = breakoutId;
+        UPDATE battles SET updated_date = NOW(), voting_locked = false WHERE id = battleId;
+    END IF;
+    COMMIT;
+END;
+$$;
+
+-- Skip a plans voting, archiving its votes and only locking voting once no plans remain active --
+CREATE OR REPLACE PROCEDURE skip_plan_voting(battleId UUID, planId UUID)
+LANGUAGE plpgsql AS $$
+DECLARE plansActive BOOL;
+BEGIN
+    -- set current active to false
+    UPDATE plans SET updated_date = NOW(), active = false, skipped = true, voteend_time = NOW() WHERE id = planId;
+    IF EXISTS (SELECT 1 FROM plans WHERE id = planId AND votes <> '[]'::jsonb) THEN
+        CALL archive_plan_vote_round(planId);
+    END IF;
+    UPDATE battle_breakout SET active_plan_id = null WHERE battle_id = battleId AND active_plan_id = planId;
+    plansActive := EXISTS (SELECT 1 FROM plans WHERE battle_id = battleId AND active = true);
+    -- set battle VotingLocked and reset activePlanId when it was the skipped plan
+    UPDATE battles
+    SET updated_date = NOW(), voting_locked = NOT plansActive,
+        active_plan_id = CASE WHEN active_plan_id = planId THEN NULL ELSE active_plan_id END,
+        voting_deadline = CASE WHEN plansActive THEN voting_deadline ELSE NULL END
+    WHERE id = battleId;
+    COMMIT;
+END;
+$$;
+
+-- Finalize a plans points, only resetting the active plan of the battle or breakout it was active in --
+CREATE OR REPLACE PROCEDURE finalize_plan(battleId UUID, planId UUID, planPoints VARCHAR(32))
+LANGUAGE plpgsql AS $$
+BEGIN
+    -- set plan points and deactivate
+    UPDATE plans SET updated_date = NOW(), active = false, points = planPoints WHERE id = planId;
+    UPDATE battle_breakout SET active_plan_id = null WHERE battle_id = battleId AND active_plan_id = planId;
+    -- reset battle active_plan_id
+    UPDATE battles SET updated_date = NOW(), active_plan_id = null WHERE id = battleId AND active_plan_id = planId;
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,13 @@
+DROP FUNCTION create_retro(ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128), retroColumns JSONB);
+CREATE FUNCTION create_retro(ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128)) RETURNS UUID
+AS $$
+DECLARE retroId UUID;
+BEGIN
+    INSERT INTO retro (owner_id, name, format, join_code) VALUES (ownerId, retroName, format, joinCode) RETURNING id INTO retroId;
+
+    RETURN retroId;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE retro DROP COLUMN columns;
+DROP TABLE retro_template;
\ No newline at end of file
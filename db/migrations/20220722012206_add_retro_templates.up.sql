@@ -0,0 +1,34 @@
+CREATE TABLE retro_template (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(256) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID REFERENCES team(id) ON DELETE CASCADE,
+    columns JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_date TIMESTAMPTZ DEFAULT NOW(),
+    updated_date TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (user_id IS NOT NULL OR team_id IS NOT NULL)
+);
+
+ALTER TABLE retro ADD COLUMN columns JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+UPDATE retro SET columns = '[
+    {"key": "start", "name": "Start", "color": "green", "icon": "", "prompt": ""},
+    {"key": "stop", "name": "Stop", "color": "red", "icon": "", "prompt": ""},
+    {"key": "continue", "name": "Continue", "color": "blue", "icon": "", "prompt": ""}
+]'::jsonb WHERE format = 'start_stop_continue';
+UPDATE retro SET columns = '[
+    {"key": "worked", "name": "Went Well", "color": "green", "icon": "smiley", "prompt": ""},
+    {"key": "improve", "name": "Needs Improvement", "color": "red", "icon": "frown", "prompt": ""},
+    {"key": "question", "name": "Question", "color": "blue", "icon": "question", "prompt": ""}
+]'::jsonb WHERE format <> 'start_stop_continue';
+
+DROP FUNCTION create_retro(ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128));
+CREATE FUNCTION create_retro(ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128), retroColumns JSONB) RETURNS UUID
+AS $$
+DECLARE retroId UUID;
+BEGIN
+    INSERT INTO retro (owner_id, name, format, join_code, columns) VALUES (ownerId, retroName, format, joinCode, retroColumns) RETURNING id INTO retroId;
+
+    RETURN retroId;
+END;
+$$ LANGUAGE plpgsql;
\ No newline at end of file
@@ -0,0 +1,32 @@
+DROP PROCEDURE edit_retro(
+    retroId UUID, retroName VARCHAR(256), joinCode VARCHAR(128),
+    maxVotes SMALLINT, allowCumulativeVoting BOOL, hideVotesDuringVoting BOOL
+);
+CREATE PROCEDURE edit_retro(retroId UUID, retroName VARCHAR(256), joinCode VARCHAR(128))
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE retro SET name = retroName, join_code = joinCode, updated_date = NOW()
+        WHERE id = retroId;
+
+    COMMIT;
+END;
+$$;
+
+DROP FUNCTION create_retro(
+    ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128), retroColumns JSONB,
+    maxVotes SMALLINT, allowCumulativeVoting BOOL, hideVotesDuringVoting BOOL
+);
+CREATE FUNCTION create_retro(ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128), retroColumns JSONB) RETURNS UUID
+AS $$
+DECLARE retroId UUID;
+BEGIN
+    INSERT INTO retro (owner_id, name, format, join_code, columns) VALUES (ownerId, retroName, format, joinCode, retroColumns) RETURNING id INTO retroId;
+
+    RETURN retroId;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE retro_group_vote DROP COLUMN count;
+ALTER TABLE retro DROP COLUMN hide_votes_during_voting;
+ALTER TABLE retro DROP COLUMN allow_cumulative_voting;
+ALTER TABLE retro DROP COLUMN max_votes;
\ No newline at end of file
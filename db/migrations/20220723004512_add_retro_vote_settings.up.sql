@@ -0,0 +1,38 @@
+ALTER TABLE retro ADD COLUMN max_votes SMALLINT NOT NULL DEFAULT 3;
+ALTER TABLE retro ADD COLUMN allow_cumulative_voting BOOL NOT NULL DEFAULT false;
+ALTER TABLE retro ADD COLUMN hide_votes_during_voting BOOL NOT NULL DEFAULT false;
+ALTER TABLE retro_group_vote ADD COLUMN count SMALLINT NOT NULL DEFAULT 1;
+
+DROP FUNCTION create_retro(ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128), retroColumns JSONB);
+CREATE FUNCTION create_retro(
+    ownerId UUID, retroName VARCHAR(256), format VARCHAR(32), joinCode VARCHAR(128), retroColumns JSONB,
+    maxVotes SMALLINT, allowCumulativeVoting BOOL, hideVotesDuringVoting BOOL
+) RETURNS UUID
+AS $$
+DECLARE retroId UUID;
+BEGIN
+    INSERT INTO retro (owner_id, name, format, join_code, columns, max_votes, allow_cumulative_voting, hide_votes_during_voting)
+        VALUES (ownerId, retroName, format, joinCode, retroColumns, maxVotes, allowCumulativeVoting, hideVotesDuringVoting)
+        RETURNING id INTO retroId;
+
+    RETURN retroId;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP PROCEDURE edit_retro(retroId UUID, retroName VARCHAR(256), joinCode VARCHAR(128));
+CREATE PROCEDURE edit_retro(
+    retroId UUID, retroName VARCHAR(256), joinCode VARCHAR(128),
+    maxVotes SMALLINT, allowCumulativeVoting BOOL, hideVotesDuringVoting BOOL
+)
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE retro SET name = retroName, join_code = joinCode,
+        max_votes = COALESCE(NULLIF(maxVotes, 0), max_votes),
+        allow_cumulative_voting = COALESCE(allowCumulativeVoting, allow_cumulative_voting),
+        hide_votes_during_voting = COALESCE(hideVotesDuringVoting, hide_votes_during_voting),
+        updated_date = NOW()
+        WHERE id = retroId;
+
+    COMMIT;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX retro_action_overdue_idx;
+ALTER TABLE retro_action_assignee DROP COLUMN overdue_reminded_due_date;
+ALTER TABLE retro_action DROP COLUMN due_date;
+ALTER TABLE retro_action DROP COLUMN priority;
+ALTER TABLE retro_action DROP COLUMN status;
\ No newline at end of file
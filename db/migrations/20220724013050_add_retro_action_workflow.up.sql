@@ -0,0 +1,10 @@
+ALTER TABLE retro_action ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'open'
+    CHECK (status IN ('open', 'in_progress', 'blocked', 'done', 'dropped'));
+ALTER TABLE retro_action ADD COLUMN priority VARCHAR(8) NOT NULL DEFAULT 'medium'
+    CHECK (priority IN ('low', 'medium', 'high'));
+ALTER TABLE retro_action ADD COLUMN due_date DATE;
+ALTER TABLE retro_action_assignee ADD COLUMN overdue_reminded_due_date DATE;
+
+UPDATE retro_action SET status = 'done' WHERE completed = true;
+
+CREATE INDEX retro_action_overdue_idx ON retro_action (due_date) WHERE completed = false;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE retro_action_review;
\ No newline at end of file
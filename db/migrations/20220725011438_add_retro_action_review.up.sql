@@ -0,0 +1,9 @@
+CREATE TABLE retro_action_review (
+    retro_id UUID NOT NULL REFERENCES retro(id) ON DELETE CASCADE,
+    action_id UUID NOT NULL REFERENCES retro_action(id) ON DELETE CASCADE,
+    prior_status VARCHAR(16) NOT NULL,
+    decision VARCHAR(8) CHECK (decision IN ('close', 'keep', 'drop')),
+    created_date TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_date TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (retro_id, action_id)
+);
\ No newline at end of file
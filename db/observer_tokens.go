@@ -0,0 +1,126 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// ObserverTokenCreate generates a new observer token for the battle, retro or storyboard,
+// only the hash of the token is stored so it's returned in full this one time
+func (d *Database) ObserverTokenCreate(EntityType string, EntityID string, UserID string, Name string) (*model.ObserverToken, error) {
+	tokenPrefix, prefixErr := randomString(8)
+	if prefixErr != nil {
+		d.logger.Error("error generating observer token prefix", zap.Error(prefixErr))
+		return nil, errors.New("error generating observer token prefix")
+	}
+
+	tokenSecret, secretErr := randomString(32)
+	if secretErr != nil {
+		d.logger.Error("error generating observer token secret", zap.Error(secretErr))
+		return nil, errors.New("error generating observer token secret")
+	}
+
+	Token := &model.ObserverToken{
+		Prefix:     tokenPrefix,
+		Name:       Name,
+		EntityType: EntityType,
+		EntityId:   EntityID,
+		UserId:     UserID,
+		Token:      tokenPrefix + "." + tokenSecret,
+	}
+	Token.Id = tokenPrefix + "." + hashString(Token.Token)
+
+	if err := d.db.QueryRow(
+		`INSERT INTO observer_token (id, entity_type, entity_id, name, user_id)
+		VALUES ($1, $2, $3, $4, $5) RETURNING created_date;`,
+		Token.Id,
+		EntityType,
+		EntityID,
+		Name,
+		UserID,
+	).Scan(&Token.CreatedDate); err != nil {
+		d.logger.Error("create observer token query error", zap.Error(err))
+		return nil, errors.New("unable to create observer token")
+	}
+
+	return Token, nil
+}
+
+// ObserverTokenList gets a list of the observer tokens for the battle, retro or storyboard
+func (d *Database) ObserverTokenList(EntityType string, EntityID string) ([]*model.ObserverToken, error) {
+	var Tokens = make([]*model.ObserverToken, 0)
+
+	rows, err := d.db.Query(
+		`SELECT id, entity_type, entity_id, COALESCE(name, ''), COALESCE(user_id::TEXT, ''), created_date
+		FROM observer_token WHERE entity_type = $1 AND entity_id = $2 ORDER BY created_date;`,
+		EntityType,
+		EntityID,
+	)
+	if err != nil {
+		d.logger.Error("get observer tokens query error", zap.Error(err))
+		return nil, errors.New("error getting observer tokens")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t model.ObserverToken
+		if err := rows.Scan(
+			&t.Id,
+			&t.EntityType,
+			&t.EntityId,
+			&t.Name,
+			&t.UserId,
+			&t.CreatedDate,
+		); err != nil {
+			d.logger.Error("observer token row scan error", zap.Error(err))
+			continue
+		}
+		t.Prefix = strings.Split(t.Id, ".")[0]
+		Tokens = append(Tokens, &t)
+	}
+
+	return Tokens, nil
+}
+
+// ObserverTokenDelete revokes an observer token for the battle, retro or storyboard
+func (d *Database) ObserverTokenDelete(EntityType string, EntityID string, TokenID string) error {
+	res, err := d.db.Exec(
+		`DELETE FROM observer_token WHERE id = $1 AND entity_type = $2 AND entity_id = $3;`,
+		TokenID,
+		EntityType,
+		EntityID,
+	)
+	if err != nil {
+		d.logger.Error("delete observer token query error", zap.Error(err))
+		return errors.New("error deleting observer token")
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errors.New("OBSERVER_TOKEN_NOT_FOUND")
+	}
+
+	return nil
+}
+
+// ConfirmObserverToken confirms the observer token is valid for the battle, retro or storyboard
+func (d *Database) ConfirmObserverToken(EntityType string, EntityID string, Token string) error {
+	var tokenId string
+	splitToken := strings.Split(Token, ".")
+
+	if err := d.db.QueryRow(
+		`SELECT id FROM observer_token WHERE id = $1 AND entity_type = $2 AND entity_id = $3;`,
+		splitToken[0]+"."+hashString(Token),
+		EntityType,
+		EntityID,
+	).Scan(&tokenId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm observer token query error", zap.Error(err))
+		}
+		return errors.New("INVALID_OBSERVER_TOKEN")
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// teamBattlePageSize the number of team battles read at a time when aggregating the teams plans
+const teamBattlePageSize = 100
+
+// SetPlanActual records the actual points and effort of a finalized plan
+func (d *Database) SetPlanActual(BattleID string, PlanID string, ActualPoints string, ActualEffort *float64) ([]*model.Plan, error) {
+	res, err := d.db.Exec(
+		`UPDATE plans SET actual_points = NULLIF($3, ''), actual_effort = $4, updated_date = NOW()
+		WHERE id = $2 AND battle_id = $1 AND points <> '' AND skipped = false;`,
+		BattleID,
+		PlanID,
+		ActualPoints,
+		ActualEffort,
+	)
+	if err != nil {
+		d.logger.Error("set plan actual query error", zap.Error(err))
+		return nil, errors.New("error setting plan actual")
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return nil, errors.New("PLAN_NOT_FINALIZED")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// TeamSetPlanActuals records the actuals of the finalized plans in the teams battles matching each reference ID,
+// returning the number of plans updated
+func (d *Database) TeamSetPlanActuals(TeamID string, Actuals []*model.PlanActual) (int, error) {
+	var updated int
+
+	for _, a := range Actuals {
+		if a.ReferenceId == "" {
+			continue
+		}
+
+		res, err := d.db.Exec(
+			`UPDATE plans p SET actual_points = NULLIF($3, ''), actual_effort = $4, updated_date = NOW()
+			FROM team_battle tb
+			WHERE tb.team_id = $1 AND p.battle_id = tb.battle_id AND p.reference_id = $2
+			AND p.points <> '' AND p.skipped = false;`,
+			TeamID,
+			a.ReferenceId,
+			a.ActualPoints,
+			a.ActualEffort,
+		)
+		if err != nil {
+			d.logger.Error("team set plan actuals query error", zap.Error(err))
+			return updated, errors.New("error setting plan actuals")
+		}
+		rows, _ := res.RowsAffected()
+		updated += int(rows)
+	}
+
+	return updated, nil
+}
+
+// TeamEstimationAccuracy compares the estimates with the actuals of the plans across all the teams battles
+func (d *Database) TeamEstimationAccuracy(TeamID string) *model.EstimationAccuracy {
+	var Plans = make([]*model.Plan, 0)
+	var UserNames = make(map[string]string)
+
+	for Offset := 0; ; Offset += teamBattlePageSize {
+		Battles := d.TeamBattleList(TeamID, teamBattlePageSize, Offset)
+		for _, b := range Battles {
+			Plans = append(Plans, d.GetPlans(b.Id, "")...)
+			for _, u := range d.GetBattleUsers(b.Id) {
+				UserNames[u.Id] = u.Name
+			}
+		}
+		if len(Battles) < teamBattlePageSize {
+			break
+		}
+	}
+
+	return calculateEstimationAccuracy(Plans, UserNames)
+}
+
+// runningAverage accumulates values to average
+type runningAverage struct {
+	sum   float64
+	count int
+}
+
+// add adds the value to the average
+func (r *runningAverage) add(v float64) {
+	r.sum += v
+	r.count++
+}
+
+// value gets the average rounded to two decimals, 0 when no values were added
+func (r *runningAverage) value() float64 {
+	if r == nil || r.count == 0 {
+		return 0
+	}
+
+	return math.Round(r.sum/float64(r.count)*100) / 100
+}
+
+// calculateEstimationAccuracy aggregates the finalized plans with recorded actuals by point value, user vote
+// and month finalized, deviations are only averaged when both the estimate and actual points are numeric
+func calculateEstimationAccuracy(Plans []*model.Plan, UserNames map[string]string) *model.EstimationAccuracy {
+	var byPoints = make(map[string]*model.PointsAccuracy)
+	var pointsActual = make(map[string]*runningAverage)
+	var pointsEffort = make(map[string]*runningAverage)
+	var byUser = make(map[string]*model.UserAccuracy)
+	var userDeviation = make(map[string]*runningAverage)
+	var byPeriod = make(map[string]*model.PeriodAccuracy)
+	var periodDeviation = make(map[string]*runningAverage)
+
+	for _, p := range Plans {
+		if p.Points == "" || p.Skipped || (p.ActualPoints == "" && p.ActualEffort == nil) {
+			continue
+		}
+
+		pa, ok := byPoints[p.Points]
+		if !ok {
+			pa = &model.PointsAccuracy{Points: p.Points}
+			byPoints[p.Points] = pa
+			pointsActual[p.Points] = &runningAverage{}
+			pointsEffort[p.Points] = &runningAverage{}
+		}
+		pa.PlanCount++
+		if p.ActualPoints == p.Points {
+			pa.ExactCount++
+		}
+		actual, actualNumeric := voteNumericValue(p.ActualPoints, nil)
+		if actualNumeric {
+			pointsActual[p.Points].add(actual)
+		}
+		if p.ActualEffort != nil {
+			pointsEffort[p.Points].add(*p.ActualEffort)
+		}
+
+		// users votes and the trend are only compared to actual points
+		if p.ActualPoints == "" {
+			continue
+		}
+
+		period := p.VoteEndTime.Format("2006-01")
+		pp, ok := byPeriod[period]
+		if !ok {
+			pp = &model.PeriodAccuracy{Period: period}
+			byPeriod[period] = pp
+			periodDeviation[period] = &runningAverage{}
+		}
+		pp.PlanCount++
+		if p.ActualPoints == p.Points {
+			pp.ExactCount++
+		}
+		if estimate, ok := voteNumericValue(p.Points, nil); ok && actualNumeric {
+			periodDeviation[period].add(math.Abs(estimate - actual))
+		}
+
+		for _, v := range p.Votes {
+			if v.VoteValue == "" || v.Dimension != "" {
+				continue
+			}
+
+			ua, ok := byUser[v.UserId]
+			if !ok {
+				ua = &model.UserAccuracy{UserId: v.UserId, UserName: UserNames[v.UserId]}
+				byUser[v.UserId] = ua
+				userDeviation[v.UserId] = &runningAverage{}
+			}
+			ua.VoteCount++
+			if v.VoteValue == p.ActualPoints {
+				ua.ExactCount++
+			}
+			if vote, ok := voteNumericValue(v.VoteValue, nil); ok && actualNumeric {
+				userDeviation[v.UserId].add(math.Abs(vote - actual))
+			}
+		}
+	}
+
+	var accuracy = &model.EstimationAccuracy{
+		ByPoints: make([]*model.PointsAccuracy, 0, len(byPoints)),
+		ByUser:   make([]*model.UserAccuracy, 0, len(byUser)),
+		OverTime: make([]*model.PeriodAccuracy, 0, len(byPeriod)),
+	}
+	for points, pa := range byPoints {
+		pa.AverageActualPoints = pointsActual[points].value()
+		pa.AverageActualEffort = pointsEffort[points].value()
+		accuracy.ByPoints = append(accuracy.ByPoints, pa)
+	}
+	for UserID, ua := range byUser {
+		ua.AverageDeviation = userDeviation[UserID].value()
+		accuracy.ByUser = append(accuracy.ByUser, ua)
+	}
+	for period, pp := range byPeriod {
+		pp.AverageDeviation = periodDeviation[period].value()
+		accuracy.OverTime = append(accuracy.OverTime, pp)
+	}
+
+	// numeric point values are ordered by value followed by the non-numeric ones
+	sort.Slice(accuracy.ByPoints, func(i, j int) bool {
+		a, aNumeric := voteNumericValue(accuracy.ByPoints[i].Points, nil)
+		b, bNumeric := voteNumericValue(accuracy.ByPoints[j].Points, nil)
+		if aNumeric && bNumeric {
+			return a < b
+		}
+		if aNumeric != bNumeric {
+			return aNumeric
+		}
+		return accuracy.ByPoints[i].Points < accuracy.ByPoints[j].Points
+	})
+	sort.Slice(accuracy.ByUser, func(i, j int) bool {
+		if accuracy.ByUser[i].UserName != accuracy.ByUser[j].UserName {
+			return accuracy.ByUser[i].UserName < accuracy.ByUser[j].UserName
+		}
+		return accuracy.ByUser[i].UserId < accuracy.ByUser[j].UserId
+	})
+	sort.Slice(accuracy.OverTime, func(i, j int) bool {
+		return accuracy.OverTime[i].Period < accuracy.OverTime[j].Period
+	})
+
+	return accuracy
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+)
+
+// TestCalculateEstimationAccuracy calls calculateEstimationAccuracy and makes sure plans are grouped
+// by their points, votes are compared to the actual points and plans without actuals are ignored
+func TestCalculateEstimationAccuracy(t *testing.T) {
+	Effort := 12.0
+	Finalized := time.Date(2022, time.July, 12, 0, 0, 0, 0, time.UTC)
+	Plans := []*model.Plan{
+		{Points: "5", ActualPoints: "8", ActualEffort: &Effort, VoteEndTime: Finalized, Votes: []*model.Vote{
+			{UserId: "a", VoteValue: "8"},
+			{UserId: "b", VoteValue: "3"},
+		}},
+		{Points: "5", ActualPoints: "5", VoteEndTime: Finalized, Votes: []*model.Vote{
+			{UserId: "a", VoteValue: "5"},
+		}},
+		{Points: "3", VoteEndTime: Finalized},
+		{Points: "8", ActualPoints: "13", Skipped: true},
+	}
+
+	Accuracy := calculateEstimationAccuracy(Plans, map[string]string{"a": "Thor", "b": "Loki"})
+
+	if len(Accuracy.ByPoints) != 1 {
+		t.Fatalf(`expected ByPoints length: %d to match 1`, len(Accuracy.ByPoints))
+	}
+	Five := Accuracy.ByPoints[0]
+	if Five.PlanCount != 2 || Five.ExactCount != 1 || Five.AverageActualPoints != 6.5 || Five.AverageActualEffort != 12 {
+		t.Fatalf(`expected 5 points accuracy: %+v to have 2 plans, 1 exact, 6.5 actual points and 12 actual effort`, Five)
+	}
+
+	if len(Accuracy.ByUser) != 2 || Accuracy.ByUser[0].UserName != "Loki" || Accuracy.ByUser[1].UserName != "Thor" {
+		t.Fatalf(`expected ByUser to be Loki then Thor`)
+	}
+	if Accuracy.ByUser[0].AverageDeviation != 5 || Accuracy.ByUser[1].ExactCount != 2 || Accuracy.ByUser[1].AverageDeviation != 0 {
+		t.Fatalf(`expected Loki to deviate by 5 and Thor to be exact twice`)
+	}
+
+	if len(Accuracy.OverTime) != 1 || Accuracy.OverTime[0].Period != "2022-07" || Accuracy.OverTime[0].AverageDeviation != 1.5 {
+		t.Fatalf(`expected one 2022-07 period with a 1.5 average deviation`)
+	}
+}
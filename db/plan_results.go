@@ -0,0 +1,122 @@
+package db
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+)
+
+// voteNumericValue gets the numeric value of a vote from the scale weights or by parsing it
+func voteNumericValue(VoteValue string, Weights map[string]float64) (float64, bool) {
+	if w, ok := Weights[VoteValue]; ok {
+		return w, true
+	}
+	if VoteValue == "1/2" || VoteValue == "½" {
+		return 0.5, true
+	}
+	n, err := strconv.ParseFloat(VoteValue, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// lowVoteConfidence votes with a confidence level at or below are counted as low confidence
+const lowVoteConfidence = 2
+
+// calculateVoteResults computes the vote statistics for a plan, values are ordered by the battles point values
+// for median, min and max while only numeric values are averaged using the battles rounding (ceil by default)
+func calculateVoteResults(Votes []*model.Vote, Spectators map[string]bool, PointValues []string, Weights map[string]float64, Rounding string) *model.PlanVoteResults {
+	var results = &model.PlanVoteResults{
+		Mode:         make([]string, 0),
+		Distribution: make(map[string]int),
+	}
+
+	valueOrder := make(map[string]int)
+	for i, v := range PointValues {
+		valueOrder[v] = i
+	}
+	orderOf := func(v string) int {
+		if i, ok := valueOrder[v]; ok {
+			return i
+		}
+		return len(PointValues)
+	}
+
+	var ranked []string
+	var sum float64
+	var numericCount int
+	var confidenceSum int
+	for _, vote := range Votes {
+		if Spectators[vote.UserId] || vote.VoteValue == "" {
+			continue
+		}
+		results.VoteCount++
+		results.Distribution[vote.VoteValue]++
+
+		if vote.Confidence != nil {
+			results.ConfidenceCount++
+			confidenceSum += *vote.Confidence
+			if *vote.Confidence <= lowVoteConfidence {
+				results.LowConfidence++
+			}
+		}
+
+		if vote.VoteValue == "?" {
+			continue
+		}
+		ranked = append(ranked, vote.VoteValue)
+		if n, ok := voteNumericValue(vote.VoteValue, Weights); ok {
+			sum += n
+			numericCount++
+		}
+	}
+
+	if numericCount > 0 {
+		average := sum / float64(numericCount)
+		switch Rounding {
+		case "round":
+			results.Average = math.Round(average)
+		case "floor":
+			results.Average = math.Floor(average)
+		default:
+			results.Average = math.Ceil(average)
+		}
+	}
+
+	if results.ConfidenceCount > 0 {
+		results.AverageConfidence = math.Round(float64(confidenceSum)/float64(results.ConfidenceCount)*100) / 100
+	}
+
+	if len(ranked) > 0 {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			oi, oj := orderOf(ranked[i]), orderOf(ranked[j])
+			if oi != oj {
+				return oi < oj
+			}
+			return ranked[i] < ranked[j]
+		})
+		results.Min = ranked[0]
+		results.Max = ranked[len(ranked)-1]
+		results.Median = ranked[(len(ranked)-1)/2]
+
+		var highest int
+		for _, v := range ranked {
+			if c := results.Distribution[v]; c > highest {
+				highest = c
+			}
+		}
+		for i, v := range ranked {
+			if results.Distribution[v] == highest && (i == 0 || ranked[i-1] != v) {
+				results.Mode = append(results.Mode, v)
+			}
+		}
+	}
+
+	results.Consensus = len(ranked) > 0 && len(ranked) == results.VoteCount && results.Min == results.Max
+
+	return results
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+)
+
+// TestCalculateVoteResults calls calculateVoteResults and makes sure spectators and undecided votes
+// are excluded from the statistics and values are ordered by the battles point values
+func TestCalculateVoteResults(t *testing.T) {
+	PointValues := []string{"0", "1/2", "1", "2", "3", "5", "8", "13", "?"}
+	Votes := []*model.Vote{
+		{UserId: "a", VoteValue: "1/2"},
+		{UserId: "b", VoteValue: "3"},
+		{UserId: "c", VoteValue: "13"},
+		{UserId: "d", VoteValue: "3"},
+		{UserId: "e", VoteValue: "?"},
+		{UserId: "f", VoteValue: "8"},
+	}
+	Spectators := map[string]bool{"f": true}
+
+	Results := calculateVoteResults(Votes, Spectators, PointValues, nil, "round")
+
+	if Results.VoteCount != 5 {
+		t.Fatalf(`expected VoteCount: %d to match 5`, Results.VoteCount)
+	}
+	if Results.Average != 5 {
+		t.Fatalf(`expected Average: %v to match 5`, Results.Average)
+	}
+	if Results.Min != "1/2" || Results.Max != "13" || Results.Median != "3" {
+		t.Fatalf(`expected Min: %s, Max: %s, Median: %s to match 1/2, 13, 3`, Results.Min, Results.Max, Results.Median)
+	}
+	if len(Results.Mode) != 1 || Results.Mode[0] != "3" {
+		t.Fatalf(`expected Mode: %v to match [3]`, Results.Mode)
+	}
+	if Results.Distribution["?"] != 1 || Results.Distribution["3"] != 2 || Results.Distribution["8"] != 0 {
+		t.Fatalf(`expected Distribution: %v to count ? once, 3 twice and exclude spectators`, Results.Distribution)
+	}
+	if Results.Consensus {
+		t.Fatalf(`expected Consensus to be false`)
+	}
+}
+
+// TestCalculateVoteResultsConsensus calls calculateVoteResults with non-numeric weighted values
+// and makes sure identical votes are a consensus and weights are used for the average
+func TestCalculateVoteResultsConsensus(t *testing.T) {
+	PointValues := []string{"S", "M", "L", "XL"}
+	Weights := map[string]float64{"S": 1, "M": 2, "L": 3.5, "XL": 5}
+	Votes := []*model.Vote{
+		{UserId: "a", VoteValue: "L"},
+		{UserId: "b", VoteValue: "L"},
+	}
+
+	Results := calculateVoteResults(Votes, nil, PointValues, Weights, "")
+
+	if !Results.Consensus {
+		t.Fatalf(`expected Consensus to be true`)
+	}
+	if Results.Average != 4 {
+		t.Fatalf(`expected ceil Average: %v to match 4`, Results.Average)
+	}
+	if Results.Median != "L" {
+		t.Fatalf(`expected Median: %s to match L`, Results.Median)
+	}
+}
+
+// TestCalculateVoteResultsConfidence calls calculateVoteResults and makes sure the confidence levels
+// are averaged and low confidence votes counted, ignoring votes without a confidence level
+func TestCalculateVoteResultsConfidence(t *testing.T) {
+	low, mid, high := 1, 3, 5
+	Votes := []*model.Vote{
+		{UserId: "a", VoteValue: "3", Confidence: &low},
+		{UserId: "b", VoteValue: "5", Confidence: &mid},
+		{UserId: "c", VoteValue: "5", Confidence: &high},
+		{UserId: "d", VoteValue: "8"},
+	}
+
+	Results := calculateVoteResults(Votes, nil, []string{"3", "5", "8"}, nil, "")
+
+	if Results.ConfidenceCount != 3 {
+		t.Fatalf(`expected ConfidenceCount: %d to match 3`, Results.ConfidenceCount)
+	}
+	if Results.AverageConfidence != 3 {
+		t.Fatalf(`expected AverageConfidence: %v to match 3`, Results.AverageConfidence)
+	}
+	if Results.LowConfidence != 1 {
+		t.Fatalf(`expected LowConfidence: %d to match 1`, Results.LowConfidence)
+	}
+}
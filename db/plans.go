@@ -3,6 +3,8 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/StevenWeathers/thunderdome-planning-poker/model"
 	"go.uber.org/zap"
@@ -11,10 +13,30 @@ import (
 // GetPlans retrieves plans for given battle
 func (d *Database) GetPlans(BattleID string, UserID string) []*model.Plan {
 	var plans = make([]*model.Plan, 0)
+	var AnonymousVoting bool
+	if err := d.db.QueryRow(
+		`SELECT anonymous_voting FROM battles WHERE id = $1`, BattleID,
+	).Scan(&AnonymousVoting); err != nil && err != sql.ErrNoRows {
+		d.logger.Error("get battle anonymous voting query error", zap.Error(err))
+	}
 	planRows, plansErr := d.db.Query(
 		`SELECT
-			id, name, type, reference_id, link, description, acceptance_criteria, points, active, skipped, votestart_time, voteend_time, votes
-			FROM plans WHERE battle_id = $1 ORDER BY created_date
+			id, name, type, reference_id, link, description, acceptance_criteria, points, active, skipped, votestart_time, voteend_time, votes, vote_results,
+			COALESCE(
+				(SELECT json_agg(json_build_object(
+					'round', pvr.round, 'votes', pvr.votes, 'results', pvr.results,
+					'startTime', pvr.start_time, 'endTime', pvr.end_time
+				) ORDER BY pvr.round) FROM plan_vote_round pvr WHERE pvr.plan_id = plans.id), '[]'
+			) AS rounds,
+			COALESCE(
+				(SELECT json_agg(json_build_object(
+					'id', pc.id, 'planId', pc.plan_id, 'userId', pc.user_id, 'comment', pc.comment,
+					'createdDate', pc.created_date, 'updatedDate', pc.updated_date
+				) ORDER BY pc.created_date) FROM plan_comment pc WHERE pc.plan_id = plans.id), '[]'
+			) AS comments,
+			dimension_points, dimension_results, COALESCE(actual_points, ''), actual_effort,
+			COALESCE(breakout_id::TEXT, '')
+			FROM plans WHERE battle_id = $1 ORDER BY sort_order, created_date
 		`,
 		BattleID,
 	)
@@ -22,17 +44,26 @@ func (d *Database) GetPlans(BattleID string, UserID string) []*model.Plan {
 		defer planRows.Close()
 		for planRows.Next() {
 			var v string
+			var VoteResults sql.NullString
+			var rounds string
+			var comments string
+			var dimensionPoints string
+			var DimensionResults sql.NullString
 			var ReferenceID sql.NullString
 			var Link sql.NullString
 			var Description sql.NullString
 			var AcceptanceCriteria sql.NullString
+			var ActualEffort sql.NullFloat64
 			var p = &model.Plan{
-				Votes:   make([]*model.Vote, 0),
-				Active:  false,
-				Skipped: false,
+				Votes:           make([]*model.Vote, 0),
+				Active:          false,
+				Skipped:         false,
+				Rounds:          make([]*model.PlanVoteRound, 0),
+				Comments:        make([]*model.PlanComment, 0),
+				DimensionPoints: make(map[string]string),
 			}
 			if err := planRows.Scan(
-				&p.Id, &p.Name, &p.Type, &ReferenceID, &Link, &Description, &AcceptanceCriteria, &p.Points, &p.Active, &p.Skipped, &p.VoteStartTime, &p.VoteEndTime, &v,
+				&p.Id, &p.Name, &p.Type, &ReferenceID, &Link, &Description, &AcceptanceCriteria, &p.Points, &p.Active, &p.Skipped, &p.VoteStartTime, &p.VoteEndTime, &v, &VoteResults, &rounds, &comments, &dimensionPoints, &DimensionResults, &p.ActualPoints, &ActualEffort, &p.BreakoutId,
 			); err != nil {
 				d.logger.Error("get battle plans query error", zap.Error(err))
 			} else {
@@ -40,15 +71,55 @@ func (d *Database) GetPlans(BattleID string, UserID string) []*model.Plan {
 				p.Link = Link.String
 				p.Description = Description.String
 				p.AcceptanceCriteria = AcceptanceCriteria.String
+				if ActualEffort.Valid {
+					p.ActualEffort = &ActualEffort.Float64
+				}
 				err = json.Unmarshal([]byte(v), &p.Votes)
 				if err != nil {
 					d.logger.Error("get battle plans query scan error", zap.Error(err))
 				}
+				if VoteResults.Valid {
+					err = json.Unmarshal([]byte(VoteResults.String), &p.Results)
+					if err != nil {
+						d.logger.Error("get battle plans vote results scan error", zap.Error(err))
+					}
+				}
+				err = json.Unmarshal([]byte(rounds), &p.Rounds)
+				if err != nil {
+					d.logger.Error("get battle plans vote rounds scan error", zap.Error(err))
+				}
+				err = json.Unmarshal([]byte(comments), &p.Comments)
+				if err != nil {
+					d.logger.Error("get battle plans comments scan error", zap.Error(err))
+				}
+				err = json.Unmarshal([]byte(dimensionPoints), &p.DimensionPoints)
+				if err != nil {
+					d.logger.Error("get battle plans dimension points scan error", zap.Error(err))
+				}
+				if DimensionResults.Valid {
+					err = json.Unmarshal([]byte(DimensionResults.String), &p.DimensionResults)
+					if err != nil {
+						d.logger.Error("get battle plans dimension results scan error", zap.Error(err))
+					}
+				}
 
 				// don't send others vote values to client, prevent sneaky devs from peaking at votes
+				// anonymous voting battles never reveal others vote values, only the results distribution
+				// confidence levels are revealed along with the vote values
 				for i := range p.Votes {
-					if p.Active && p.Votes[i].UserId != UserID {
+					if (p.Active || AnonymousVoting) && p.Votes[i].UserId != UserID {
 						p.Votes[i].VoteValue = ""
+						p.Votes[i].Confidence = nil
+					}
+				}
+				if AnonymousVoting {
+					for _, round := range p.Rounds {
+						for i := range round.Votes {
+							if round.Votes[i].UserId != UserID {
+								round.Votes[i].VoteValue = ""
+								round.Votes[i].Confidence = nil
+							}
+						}
 					}
 				}
 
@@ -75,7 +146,53 @@ func (d *Database) CreatePlan(BattleID string, PlanName string, PlanType string,
 	return plans, nil
 }
 
-// ActivatePlanVoting sets the plan by ID to active, wipes any previous votes/points, and disables votingLock
+// CreatePlans adds multiple plans to a battle in a single transaction, in the order given
+func (d *Database) CreatePlans(BattleID string, Plans []*model.Plan) ([]*model.Plan, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		d.logger.Error("create plans transaction error", zap.Error(err))
+		return nil, errors.New("unable to create plans")
+	}
+
+	for _, plan := range Plans {
+		SanitizedDescription := d.htmlSanitizerPolicy.Sanitize(plan.Description)
+		SanitizedAcceptanceCriteria := d.htmlSanitizerPolicy.Sanitize(plan.AcceptanceCriteria)
+		if _, err := tx.Exec(
+			`call create_plan($1, $2, $3, $4, $5, $6, $7);`, BattleID, plan.Name, plan.Type, plan.ReferenceId, plan.Link, SanitizedDescription, SanitizedAcceptanceCriteria,
+		); err != nil {
+			d.logger.Error("call create_plan error", zap.Error(err))
+			tx.Rollback()
+			return nil, errors.New("unable to create plans")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error("create plans commit error", zap.Error(err))
+		return nil, errors.New("unable to create plans")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// ReorderPlans sets the battles plan order to the order of the given plan IDs
+func (d *Database) ReorderPlans(BattleID string, PlanIDs []string) ([]*model.Plan, error) {
+	var planIdsJSON, _ = json.Marshal(PlanIDs)
+
+	if _, err := d.db.Exec(
+		`call reorder_plans($1, $2);`, BattleID, string(planIdsJSON),
+	); err != nil {
+		d.logger.Error("call reorder_plans error", zap.Error(err))
+		return nil, errors.New("unable to reorder plans")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// ActivatePlanVoting sets the plan by ID to active, archives then wipes any previous votes/points, and disables votingLock
 func (d *Database) ActivatePlanVoting(BattleID string, PlanID string) ([]*model.Plan, error) {
 	if _, err := d.db.Exec(
 		`call activate_plan_voting($1, $2);`, BattleID, PlanID,
@@ -88,28 +205,64 @@ func (d *Database) ActivatePlanVoting(BattleID string, PlanID string) ([]*model.
 	return plans, nil
 }
 
-// SetVote sets a users vote for the plan
-func (d *Database) SetVote(BattleID string, UserID string, PlanID string, VoteValue string) (BattlePlans []*model.Plan, AllUsersVoted bool) {
+// RevotePlan opens a fresh voting round for a plan that has already been voted on
+func (d *Database) RevotePlan(BattleID string, PlanID string) ([]*model.Plan, error) {
+	var RoundCount int
+	if err := d.db.QueryRow(
+		`SELECT COUNT(pvr.id) FROM plan_vote_round pvr
+		JOIN plans p ON p.id = pvr.plan_id
+		WHERE pvr.plan_id = $1 AND p.battle_id = $2;`,
+		PlanID, BattleID,
+	).Scan(&RoundCount); err != nil {
+		d.logger.Error("get plan vote round count error", zap.Error(err))
+		return nil, errors.New("unable to revote plan")
+	}
+
+	if RoundCount == 0 {
+		return nil, errors.New("PLAN_NOT_VOTED")
+	}
+
+	return d.ActivatePlanVoting(BattleID, PlanID)
+}
+
+// SetVote sets a users vote for the plan with an optional confidence level,
+// in battles with estimation dimensions it's the users vote for that dimension
+func (d *Database) SetVote(BattleID string, UserID string, PlanID string, VoteValue string, Dimension string, Confidence *int) (BattlePlans []*model.Plan, AllUsersVoted bool) {
 	if _, err := d.db.Exec(
-		`call set_user_vote($1, $2, $3);`, PlanID, UserID, VoteValue); err != nil {
+		`call set_user_vote($1, $2, $3, $4, $5);`, PlanID, UserID, VoteValue, Dimension, Confidence); err != nil {
 		d.logger.Error("call set_user_vote error", zap.Error(err))
 	}
 
 	Plans := d.GetPlans(BattleID, "")
 	ActiveUsers := d.GetBattleActiveUsers(BattleID)
 
+	// async voting plans are voted on by everyone that joined the battle not just those currently connected
+	if VotingMode, _ := d.GetBattleVotingMode(BattleID); VotingMode == "async" {
+		ActiveUsers = d.getBattleVoters(BattleID)
+	}
+
+	// users have voted once they've voted on every dimension
+	RequiredVotes := 1
+	if Dimensions, _, err := d.GetBattleDimensions(BattleID); err == nil && len(Dimensions) > 0 {
+		RequiredVotes = len(Dimensions)
+	}
+
 	// determine if all active users have voted
 	AllVoted := true
 	for _, plan := range Plans {
 		if plan.Id == PlanID {
-			activePlanVoters := make(map[string]bool)
+			activePlanVoters := make(map[string]int)
 
 			for _, vote := range plan.Votes {
 				var UserID string = vote.UserId
-				activePlanVoters[UserID] = true
+				activePlanVoters[UserID]++
 			}
 			for _, war := range ActiveUsers {
-				if _, UserVoted := activePlanVoters[war.Id]; !UserVoted && !war.Spectator {
+				// breakout plans are only voted on by the users in that breakout
+				if war.BreakoutId != plan.BreakoutId {
+					continue
+				}
+				if activePlanVoters[war.Id] < RequiredVotes && !war.Spectator {
 					AllVoted = false
 					break
 				}
@@ -121,6 +274,61 @@ func (d *Database) SetVote(BattleID string, UserID string, PlanID string, VoteVa
 	return Plans, AllVoted
 }
 
+// getBattleVoters gets the battle users that haven't abandoned the battle
+func (d *Database) getBattleVoters(BattleID string) []*model.BattleUser {
+	var users = make([]*model.BattleUser, 0)
+
+	rows, err := d.db.Query(
+		`SELECT user_id, spectator, COALESCE(breakout_id::TEXT, '') FROM battles_users WHERE battle_id = $1 AND abandoned = false`,
+		BattleID,
+	)
+	if err != nil {
+		d.logger.Error("get battle voters query error", zap.Error(err))
+		return users
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u model.BattleUser
+		if err := rows.Scan(&u.Id, &u.Spectator, &u.BreakoutId); err != nil {
+			d.logger.Error("get battle voters scan error", zap.Error(err))
+			continue
+		}
+		users = append(users, &u)
+	}
+
+	return users
+}
+
+// ConfirmPlanActive confirms the plan is open for voting
+func (d *Database) ConfirmPlanActive(BattleID string, PlanID string) error {
+	var Active bool
+
+	if err := d.db.QueryRow(
+		`SELECT active FROM plans WHERE id = $1 AND battle_id = $2;`, PlanID, BattleID,
+	).Scan(&Active); err != nil || !Active {
+		return errors.New("PLAN_NOT_ACTIVE")
+	}
+
+	return nil
+}
+
+// StartAsyncPlanVoting opens voting on multiple plans at once until the deadline
+func (d *Database) StartAsyncPlanVoting(BattleID string, PlanIDs []string, VotingDeadline time.Time) ([]*model.Plan, error) {
+	var planIdsJSON, _ = json.Marshal(PlanIDs)
+
+	if _, err := d.db.Exec(
+		`call start_async_plan_voting($1, $2, $3);`, BattleID, string(planIdsJSON), VotingDeadline,
+	); err != nil {
+		d.logger.Error("call start_async_plan_voting error", zap.Error(err))
+		return nil, errors.New("unable to start async plan voting")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
 // RetractVote removes a users vote for the plan
 func (d *Database) RetractVote(BattleID string, UserID string, PlanID string) ([]*model.Plan, error) {
 	if _, err := d.db.Exec(
@@ -141,12 +349,19 @@ func (d *Database) EndPlanVoting(BattleID string, PlanID string) ([]*model.Plan,
 		d.logger.Error("call end_plan_voting error", zap.Error(err))
 	}
 
+	d.setPlanVoteResults(BattleID, PlanID)
+
+	if _, err := d.db.Exec(
+		`call archive_plan_vote_round($1);`, PlanID); err != nil {
+		d.logger.Error("call archive_plan_vote_round error", zap.Error(err))
+	}
+
 	plans := d.GetPlans(BattleID, "")
 
 	return plans, nil
 }
 
-// SkipPlan sets plan to active: false and unsets battle's activePlanId
+// SkipPlan sets plan to active: false, archives its votes and unsets battle's activePlanId
 func (d *Database) SkipPlan(BattleID string, PlanID string) ([]*model.Plan, error) {
 	if _, err := d.db.Exec(
 		`call skip_plan_voting($1, $2);`, BattleID, PlanID); err != nil {
@@ -185,8 +400,43 @@ func (d *Database) BurnPlan(BattleID string, PlanID string) ([]*model.Plan, erro
 	return plans, nil
 }
 
-// FinalizePlan sets plan to active: false
-func (d *Database) FinalizePlan(BattleID string, PlanID string, PlanPoints string) ([]*model.Plan, error) {
+// FinalizePlan sets plan to active: false, in battles with estimation dimensions each dimensions finalized points are stored
+// and when no points are provided they're calculated from the dimension points with the battles formula
+func (d *Database) FinalizePlan(BattleID string, PlanID string, PlanPoints string, DimensionPoints map[string]string) ([]*model.Plan, error) {
+	if len(DimensionPoints) > 0 {
+		Dimensions, Formula, err := d.GetBattleDimensions(BattleID)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range DimensionPoints {
+			dim := getBattleDimension(Dimensions, key)
+			if dim == nil || !contains(dim.PointValuesAllowed, value) {
+				return nil, errors.New("INVALID_DIMENSION_POINTS")
+			}
+		}
+
+		if PlanPoints == "" && Formula != "" {
+			var Rounding string
+			if err := d.db.QueryRow(
+				`SELECT COALESCE(point_average_rounding, 'ceil') FROM battles WHERE id = $1;`, BattleID,
+			).Scan(&Rounding); err != nil {
+				d.logger.Error("get battle point average rounding error", zap.Error(err))
+			}
+			PlanPoints, err = d.calculateDimensionPoints(Dimensions, Formula, Rounding, DimensionPoints)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var dimensionPointsJSON, _ = json.Marshal(DimensionPoints)
+		if _, err := d.db.Exec(
+			`UPDATE plans SET dimension_points = $3 WHERE id = $2 AND battle_id = $1;`, BattleID, PlanID, string(dimensionPointsJSON),
+		); err != nil {
+			d.logger.Error("update plan dimension points error", zap.Error(err))
+			return nil, errors.New("unable to set plan dimension points")
+		}
+	}
+
 	if _, err := d.db.Exec(
 		`call finalize_plan($1, $2, $3);`, BattleID, PlanID, PlanPoints); err != nil {
 		d.logger.Error("call finalize_plan error", zap.Error(err))
@@ -196,3 +446,157 @@ func (d *Database) FinalizePlan(BattleID string, PlanID string, PlanPoints strin
 
 	return plans, nil
 }
+
+// setPlanVoteResults computes and stores the plans vote statistics
+func (d *Database) setPlanVoteResults(BattleID string, PlanID string) {
+	var votes string
+	var pointValues string
+	var rounding string
+	var weights string
+
+	if err := d.db.QueryRow(`
+		SELECT p.votes, b.point_values_allowed, COALESCE(b.point_average_rounding, 'ceil'),
+		COALESCE(
+			(SELECT json_object_agg(esv.value, esv.weight) FROM estimation_scale_value esv
+			WHERE esv.scale_id = b.estimation_scale_id AND esv.weight IS NOT NULL), '{}'
+		) AS weights
+		FROM plans p
+		JOIN battles b ON b.id = p.battle_id
+		WHERE p.id = $1 AND p.battle_id = $2`,
+		PlanID, BattleID,
+	).Scan(&votes, &pointValues, &rounding, &weights); err != nil {
+		d.logger.Error("get plan vote results data error", zap.Error(err))
+		return
+	}
+
+	var Votes = make([]*model.Vote, 0)
+	var PointValues = make([]string, 0)
+	var Weights = make(map[string]float64)
+	if err := json.Unmarshal([]byte(votes), &Votes); err != nil {
+		d.logger.Error("plan votes json error", zap.Error(err))
+	}
+	if err := json.Unmarshal([]byte(pointValues), &PointValues); err != nil {
+		d.logger.Error("battle point values json error", zap.Error(err))
+	}
+	if err := json.Unmarshal([]byte(weights), &Weights); err != nil {
+		d.logger.Error("estimation scale weights json error", zap.Error(err))
+	}
+
+	Spectators := make(map[string]bool)
+	for _, u := range d.GetBattleUsers(BattleID) {
+		if u.Spectator {
+			Spectators[u.Id] = true
+		}
+	}
+
+	// dimension votes are summarized per dimension
+	var PlanVotes = make([]*model.Vote, 0)
+	for _, v := range Votes {
+		if v.Dimension == "" {
+			PlanVotes = append(PlanVotes, v)
+		}
+	}
+	d.setPlanDimensionResults(BattleID, PlanID, Votes, Spectators, rounding)
+
+	Results := calculateVoteResults(PlanVotes, Spectators, PointValues, Weights, rounding)
+	resultsJSON, _ := json.Marshal(Results)
+
+	if _, err := d.db.Exec(
+		`UPDATE plans SET vote_results = $2 WHERE id = $1;`, PlanID, string(resultsJSON),
+	); err != nil {
+		d.logger.Error("update plan vote results error", zap.Error(err))
+	}
+}
+
+// AddPlanComment adds a comment to a battle plan, the plan must belong to the battle
+func (d *Database) AddPlanComment(BattleID string, UserID string, PlanID string, Comment string) ([]*model.Plan, error) {
+	var Added bool
+	if err := d.db.QueryRow(
+		`SELECT plan_comment_add($1, $2, $3, $4);`,
+		BattleID,
+		PlanID,
+		UserID,
+		Comment,
+	).Scan(&Added); err != nil {
+		d.logger.Error("plan_comment_add query error", zap.Error(err))
+		return nil, errors.New("unable to add plan comment")
+	}
+	if !Added {
+		return nil, errors.New("PLAN_NOT_FOUND")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// EditPlanComment edits a battle plan comment
+func (d *Database) EditPlanComment(BattleID string, CommentID string, Comment string) ([]*model.Plan, error) {
+	if _, err := d.db.Exec(
+		`call plan_comment_edit($1, $2, $3);`,
+		BattleID,
+		CommentID,
+		Comment,
+	); err != nil {
+		d.logger.Error("call plan_comment_edit error", zap.Error(err))
+		return nil, errors.New("unable to edit plan comment")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// DeletePlanComment deletes a battle plan comment
+func (d *Database) DeletePlanComment(BattleID string, CommentID string) ([]*model.Plan, error) {
+	if _, err := d.db.Exec(
+		`call plan_comment_delete($1, $2);`,
+		BattleID,
+		CommentID,
+	); err != nil {
+		d.logger.Error("call plan_comment_delete error", zap.Error(err))
+		return nil, errors.New("unable to delete plan comment")
+	}
+
+	plans := d.GetPlans(BattleID, "")
+
+	return plans, nil
+}
+
+// ConfirmPlanComment confirms the comment is on the battles plan
+func (d *Database) ConfirmPlanComment(BattleID string, PlanID string, CommentID string) error {
+	var commentId string
+
+	if err := d.db.QueryRow(
+		`SELECT id FROM plan_comment WHERE id = $1 AND plan_id = $2 AND battle_id = $3;`,
+		CommentID,
+		PlanID,
+		BattleID,
+	).Scan(&commentId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm plan comment query error", zap.Error(err))
+		}
+		return errors.New("PLAN_COMMENT_NOT_FOUND")
+	}
+
+	return nil
+}
+
+// ConfirmPlanCommentAuthor confirms the user wrote the battle plan comment
+func (d *Database) ConfirmPlanCommentAuthor(BattleID string, CommentID string, UserID string) error {
+	var commentId string
+
+	if err := d.db.QueryRow(
+		`SELECT id FROM plan_comment WHERE id = $1 AND battle_id = $2 AND user_id = $3;`,
+		CommentID,
+		BattleID,
+		UserID,
+	).Scan(&commentId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm plan comment author query error", zap.Error(err))
+		}
+		return errors.New("REQUIRES_COMMENT_AUTHOR")
+	}
+
+	return nil
+}
@@ -1,16 +1,29 @@
 package db
 
 import (
+	"encoding/json"
 	"errors"
 
 	"github.com/StevenWeathers/thunderdome-planning-poker/model"
 	"go.uber.org/zap"
 )
 
-// RetroCreate adds a new retro to the db
-func (d *Database) RetroCreate(OwnerID string, RetroName string, Format string, JoinCode string) (*model.Retro, error) {
+// RetroCreate adds a new retro to the db, when no columns are provided the built-in formats columns are used
+func (d *Database) RetroCreate(
+	OwnerID string, RetroName string, Format string, JoinCode string, Columns []*model.RetroColumn,
+	MaxVotes int, AllowCumulativeVoting bool, HideVotesDuringVoting bool,
+) (*model.Retro, error) {
 	var encryptedJoinCode string
 
+	if len(Columns) == 0 {
+		FormatColumns, ok := retroFormatColumns[Format]
+		if !ok {
+			return nil, errors.New("INVALID_RETRO_FORMAT")
+		}
+		Columns = FormatColumns
+	}
+	columnsJSON, _ := json.Marshal(Columns)
+
 	if JoinCode != "" {
 		EncryptedCode, codeErr := encrypt(JoinCode, d.config.AESHashkey)
 		if codeErr != nil {
@@ -20,21 +33,30 @@ func (d *Database) RetroCreate(OwnerID string, RetroName string, Format string,
 	}
 
 	var b = &model.Retro{
-		OwnerID:     OwnerID,
-		Name:        RetroName,
-		Format:      "worked_improve_question",
-		Phase:       "intro",
-		Users:       make([]*model.RetroUser, 0),
-		Items:       make([]*model.RetroItem, 0),
-		ActionItems: make([]*model.RetroAction, 0),
+		OwnerID:               OwnerID,
+		Name:                  RetroName,
+		Format:                Format,
+		Columns:               Columns,
+		Phase:                 "intro",
+		MaxVotes:              MaxVotes,
+		AllowCumulativeVoting: AllowCumulativeVoting,
+		HideVotesDuringVoting: HideVotesDuringVoting,
+		Users:                 make([]*model.RetroUser, 0),
+		Items:                 make([]*model.RetroItem, 0),
+		ActionItems:           make([]*model.RetroAction, 0),
+		ReviewActions:         make([]*model.RetroReviewAction, 0),
 	}
 
 	e := d.db.QueryRow(
-		`SELECT * FROM create_retro($1, $2, $3, $4);`,
+		`SELECT * FROM create_retro($1, $2, $3, $4, $5, $6, $7, $8);`,
 		OwnerID,
 		RetroName,
 		Format,
 		encryptedJoinCode,
+		string(columnsJSON),
+		MaxVotes,
+		AllowCumulativeVoting,
+		HideVotesDuringVoting,
 	).Scan(&b.Id)
 	if e != nil {
 		d.logger.Error("create retro error", zap.Error(e))
@@ -58,7 +80,10 @@ func (d *Database) RetroCreate(OwnerID string, RetroName string, Format string,
 }
 
 // EditRetro updates the retro by ID
-func (d *Database) EditRetro(RetroID string, RetroName string, JoinCode string) error {
+func (d *Database) EditRetro(
+	RetroID string, RetroName string, JoinCode string,
+	MaxVotes int, AllowCumulativeVoting *bool, HideVotesDuringVoting *bool,
+) error {
 	var encryptedJoinCode string
 
 	if JoinCode != "" {
@@ -69,8 +94,9 @@ func (d *Database) EditRetro(RetroID string, RetroName string, JoinCode string)
 		encryptedJoinCode = EncryptedCode
 	}
 
-	if _, err := d.db.Exec(`call edit_retro($1, $2, $3);`,
+	if _, err := d.db.Exec(`call edit_retro($1, $2, $3, $4, $5, $6);`,
 		RetroID, RetroName, encryptedJoinCode,
+		MaxVotes, AllowCumulativeVoting, HideVotesDuringVoting,
 	); err != nil {
 		d.logger.Error("update retro error", zap.Error(err))
 		return errors.New("unable to edit retro")
@@ -82,18 +108,22 @@ func (d *Database) EditRetro(RetroID string, RetroName string, JoinCode string)
 // RetroGet gets a retro by ID
 func (d *Database) RetroGet(RetroID string) (*model.Retro, error) {
 	var b = &model.Retro{
-		Id:          RetroID,
-		Users:       make([]*model.RetroUser, 0),
-		Items:       make([]*model.RetroItem, 0),
-		Groups:      make([]*model.RetroGroup, 0),
-		ActionItems: make([]*model.RetroAction, 0),
-		Votes:       make([]*model.RetroVote, 0),
+		Id:            RetroID,
+		Users:         make([]*model.RetroUser, 0),
+		Items:         make([]*model.RetroItem, 0),
+		Groups:        make([]*model.RetroGroup, 0),
+		ActionItems:   make([]*model.RetroAction, 0),
+		ReviewActions: make([]*model.RetroReviewAction, 0),
+		Votes:         make([]*model.RetroVote, 0),
+		Columns:       make([]*model.RetroColumn, 0),
 	}
+	var columns string
 
 	// get retro
 	e := d.db.QueryRow(
 		`SELECT
-			id, name, owner_id, format, phase, COALESCE(join_code, ''), created_date, updated_date
+			id, name, owner_id, format, columns, phase, max_votes, allow_cumulative_voting, hide_votes_during_voting,
+			COALESCE(join_code, ''), created_date, updated_date
 		FROM retro WHERE id = $1`,
 		RetroID,
 	).Scan(
@@ -101,7 +131,11 @@ func (d *Database) RetroGet(RetroID string) (*model.Retro, error) {
 		&b.Name,
 		&b.OwnerID,
 		&b.Format,
+		&columns,
 		&b.Phase,
+		&b.MaxVotes,
+		&b.AllowCumulativeVoting,
+		&b.HideVotesDuringVoting,
 		&b.JoinCode,
 		&b.CreatedDate,
 		&b.UpdatedDate,
@@ -109,6 +143,7 @@ func (d *Database) RetroGet(RetroID string) (*model.Retro, error) {
 	if e != nil {
 		return nil, e
 	}
+	_ = json.Unmarshal([]byte(columns), &b.Columns)
 
 	if b.JoinCode != "" {
 		DecryptedCode, codeErr := decrypt(b.JoinCode, d.config.AESHashkey)
@@ -122,11 +157,49 @@ func (d *Database) RetroGet(RetroID string) (*model.Retro, error) {
 	b.Groups = d.GetRetroGroups(RetroID)
 	b.Users = d.RetroGetUsers(RetroID)
 	b.ActionItems = d.GetRetroActions(RetroID)
+	b.ReviewActions = d.GetRetroReviewActions(RetroID)
 	b.Votes = d.GetRetroVotes(RetroID)
 
 	return b, nil
 }
 
+// GetRetroVoteSettings gets the retros phase and vote settings
+func (d *Database) GetRetroVoteSettings(RetroID string) (*model.Retro, error) {
+	var b = &model.Retro{
+		Id: RetroID,
+	}
+
+	if err := d.db.QueryRow(
+		`SELECT phase, max_votes, allow_cumulative_voting, hide_votes_during_voting FROM retro WHERE id = $1;`,
+		RetroID,
+	).Scan(
+		&b.Phase,
+		&b.MaxVotes,
+		&b.AllowCumulativeVoting,
+		&b.HideVotesDuringVoting,
+	); err != nil {
+		d.logger.Error("get retro vote settings query error", zap.Error(err))
+		return nil, errors.New("RETRO_NOT_FOUND")
+	}
+
+	return b, nil
+}
+
+// GetRetroPhase gets the retros current phase
+func (d *Database) GetRetroPhase(RetroID string) (string, error) {
+	var Phase string
+
+	if err := d.db.QueryRow(
+		`SELECT phase FROM retro WHERE id = $1;`,
+		RetroID,
+	).Scan(&Phase); err != nil {
+		d.logger.Error("get retro phase query error", zap.Error(err))
+		return "", errors.New("RETRO_NOT_FOUND")
+	}
+
+	return Phase, nil
+}
+
 // RetroGetByUser gets a list of retros by UserID
 func (d *Database) RetroGetByUser(UserID string) ([]*model.Retro, error) {
 	var retros = make([]*model.Retro, 0)
@@ -2,6 +2,10 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
 	"github.com/StevenWeathers/thunderdome-planning-poker/model"
 	"go.uber.org/zap"
 )
@@ -19,11 +23,19 @@ func (d *Database) CreateRetroAction(RetroID string, UserID string, Content stri
 	return actions, nil
 }
 
-// UpdateRetroAction updates an actions status
-func (d *Database) UpdateRetroAction(RetroID string, ActionID string, Content string, Completed bool) (Actions []*model.RetroAction, DeleteError error) {
+// UpdateRetroAction updates an actions content, status, priority and due date,
+// the action is completed once it's done or dropped
+func (d *Database) UpdateRetroAction(
+	RetroID string, ActionID string, Content string, Status string, Priority string, DueDate *time.Time,
+) (Actions []*model.RetroAction, DeleteError error) {
 	if _, err := d.db.Exec(
-		`UPDATE retro_action SET completed = $2, content = $3, updated_date = NOW() WHERE id = $1;`, ActionID, Completed, Content); err != nil {
+		`UPDATE retro_action SET content = $2, status = $3, priority = $4,
+			completed = $3 IN ('done', 'dropped'),
+			due_date = $5, updated_date = NOW()
+		WHERE id = $1 AND retro_id = $6;`,
+		ActionID, Content, Status, Priority, DueDate, RetroID); err != nil {
 		d.logger.Error("update retro_action error", zap.Error(err))
+		return nil, errors.New("unable to update retro action")
 	}
 
 	actions := d.GetRetroActions(RetroID)
@@ -43,12 +55,40 @@ func (d *Database) DeleteRetroAction(RetroID string, userID string, ActionID str
 	return actions, nil
 }
 
+// retroActionAssigneesComments selects the retro action aliased ra's assignees and comments as json
+const retroActionAssigneesComments = `COALESCE(
+				(SELECT json_agg(json_build_object(
+					'id', u.id, 'name', u.name, 'avatar', u.avatar
+				) ORDER BY raa.created_date) FROM retro_action_assignee raa
+				JOIN users u ON u.id = raa.user_id WHERE raa.action_id = ra.id), '[]'
+			) AS assignees,
+			COALESCE(
+				(SELECT json_agg(json_build_object(
+					'id', rac.id, 'actionId', rac.action_id, 'userId', rac.user_id, 'comment', rac.comment,
+					'createdDate', rac.created_date, 'updatedDate', rac.updated_date
+				) ORDER BY rac.created_date) FROM retro_action_comment rac WHERE rac.action_id = ra.id), '[]'
+			) AS comments`
+
+// scanRetroActionAssigneesComments unmarshals the actions assignees and comments selected by retroActionAssigneesComments
+func (d *Database) scanRetroActionAssigneesComments(Action *model.RetroAction, Assignees string, Comments string) {
+	Action.Assignees = make([]*model.RetroUser, 0)
+	Action.Comments = make([]*model.RetroActionComment, 0)
+
+	if err := json.Unmarshal([]byte(Assignees), &Action.Assignees); err != nil {
+		d.logger.Error("get retro action assignees scan error", zap.Error(err))
+	}
+	if err := json.Unmarshal([]byte(Comments), &Action.Comments); err != nil {
+		d.logger.Error("get retro action comments scan error", zap.Error(err))
+	}
+}
+
 // GetRetroActions retrieves retro actions from the DB
 func (d *Database) GetRetroActions(RetroID string) []*model.RetroAction {
 	var actions = make([]*model.RetroAction, 0)
 
 	actionRows, actionsErr := d.db.Query(
-		`SELECT id, content, completed FROM retro_action WHERE retro_id = $1 ORDER BY created_date ASC;`,
+		`SELECT ra.id, ra.content, ra.completed, ra.status, ra.priority, ra.due_date, `+retroActionAssigneesComments+`
+			FROM retro_action ra WHERE ra.retro_id = $1 ORDER BY ra.created_date ASC;`,
 		RetroID,
 	)
 	if actionsErr == nil {
@@ -59,9 +99,14 @@ func (d *Database) GetRetroActions(RetroID string) []*model.RetroAction {
 				Content:   "",
 				Completed: false,
 			}
-			if err := actionRows.Scan(&ri.ID, &ri.Content, &ri.Completed); err != nil {
+			var assignees string
+			var comments string
+			if err := actionRows.Scan(
+				&ri.ID, &ri.Content, &ri.Completed, &ri.Status, &ri.Priority, &ri.DueDate, &assignees, &comments,
+			); err != nil {
 				d.logger.Error("get retro actions error", zap.Error(err))
 			} else {
+				d.scanRetroActionAssigneesComments(ri, assignees, comments)
 				actions = append(actions, ri)
 			}
 		}
@@ -70,18 +115,44 @@ func (d *Database) GetRetroActions(RetroID string) []*model.RetroAction {
 	return actions
 }
 
-// GetTeamRetroActions retrieves retro actions for the team
-func (d *Database) GetTeamRetroActions(TeamID string, Limit int, Offset int, Completed bool) ([]*model.RetroAction, int, error) {
-	var actions = make([]*model.RetroAction, 0)
+// retroActionSorts the sort orders of the team retro actions list
+var retroActionSorts = map[string]string{
+	"created":  `ra.created_date DESC`,
+	"due":      `ra.due_date ASC NULLS LAST, ra.created_date DESC`,
+	"priority": `CASE ra.priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, ra.due_date ASC NULLS LAST, ra.created_date DESC`,
+}
 
+// GetTeamRetroActions retrieves retro actions for the team, filtered by either completed or the status,
+// and optionally by priority, assignee and only those overdue
+func (d *Database) GetTeamRetroActions(
+	TeamID string, Limit int, Offset int, Completed bool,
+	Status string, Priority string, AssigneeID string, Overdue bool, Sort string,
+) ([]*model.RetroAction, int, error) {
+	var actions = make([]*model.RetroAction, 0)
 	var Count int
 
+	OrderBy, ok := retroActionSorts[Sort]
+	if !ok {
+		OrderBy = retroActionSorts["created"]
+	}
+	const where = `WHERE tr.team_id = $1
+				AND (($2 = '' AND ra.completed = $3) OR ra.status = $2)
+				AND ($4 = '' OR ra.priority = $4)
+				AND ($5 = '' OR EXISTS (
+					SELECT 1 FROM retro_action_assignee raa WHERE raa.action_id = ra.id AND raa.user_id::TEXT = $5
+				))
+				AND ($6 = false OR (ra.completed = false AND ra.due_date < CURRENT_DATE))`
+
 	e := d.db.QueryRow(
 		`SELECT COUNT(ra.*) FROM team_retro tr
-				LEFT JOIN retro_action ra ON ra.retro_id = tr.retro_id
-				WHERE tr.team_id = $1 AND ra.completed = $2;`,
+				INNER JOIN retro_action ra ON ra.retro_id = tr.retro_id
+				`+where+`;`,
 		TeamID,
+		Status,
 		Completed,
+		Priority,
+		AssigneeID,
+		Overdue,
 	).Scan(
 		&Count,
 	)
@@ -90,12 +161,19 @@ func (d *Database) GetTeamRetroActions(TeamID string, Limit int, Offset int, Com
 	}
 
 	actionRows, err := d.db.Query(
-		`SELECT ra.id, ra.content, ra.completed, tr.retro_id FROM team_retro tr
+		`SELECT ra.id, ra.content, ra.completed, ra.status, ra.priority, ra.due_date, tr.retro_id,
+				`+retroActionAssigneesComments+`
+				FROM team_retro tr
 				INNER JOIN retro_action ra ON ra.retro_id = tr.retro_id
-				WHERE tr.team_id = $1 AND ra.completed = $2 ORDER BY ra.created_date DESC
-				LIMIT $3 OFFSET $4;`,
+				`+where+`
+				ORDER BY `+OrderBy+`
+				LIMIT $7 OFFSET $8;`,
 		TeamID,
+		Status,
 		Completed,
+		Priority,
+		AssigneeID,
+		Overdue,
 		Limit,
 		Offset,
 	)
@@ -103,9 +181,15 @@ func (d *Database) GetTeamRetroActions(TeamID string, Limit int, Offset int, Com
 		defer actionRows.Close()
 		for actionRows.Next() {
 			var ri = &model.RetroAction{}
-			if err := actionRows.Scan(&ri.ID, &ri.Content, &ri.Completed, &ri.RetroID); err != nil {
+			var assignees string
+			var comments string
+			if err := actionRows.Scan(
+				&ri.ID, &ri.Content, &ri.Completed, &ri.Status, &ri.Priority, &ri.DueDate, &ri.RetroID,
+				&assignees, &comments,
+			); err != nil {
 				d.logger.Error("get retro actions error", zap.Error(err))
 			} else {
+				d.scanRetroActionAssigneesComments(ri, assignees, comments)
 				actions = append(actions, ri)
 			}
 		}
@@ -117,6 +201,89 @@ func (d *Database) GetTeamRetroActions(TeamID string, Limit int, Offset int, Com
 	return actions, Count, nil
 }
 
+// GetTeamRetroActionRetroID gets the ID of the team retro the action belongs to
+func (d *Database) GetTeamRetroActionRetroID(TeamID string, ActionID string) (string, error) {
+	var RetroID string
+
+	if err := d.db.QueryRow(
+		`SELECT ra.retro_id FROM retro_action ra
+		JOIN team_retro tr ON tr.retro_id = ra.retro_id
+		WHERE tr.team_id = $1 AND ra.id = $2;`,
+		TeamID,
+		ActionID,
+	).Scan(&RetroID); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get team retro action query error", zap.Error(err))
+		}
+		return "", errors.New("RETRO_ACTION_NOT_FOUND")
+	}
+
+	return RetroID, nil
+}
+
+// ConfirmRetroAction confirms the action belongs to the retro
+func (d *Database) ConfirmRetroAction(RetroID string, ActionID string) error {
+	var actionId string
+
+	if err := d.db.QueryRow(
+		`SELECT id FROM retro_action WHERE id = $1 AND retro_id = $2;`,
+		ActionID,
+		RetroID,
+	).Scan(&actionId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm retro action query error", zap.Error(err))
+		}
+		return errors.New("RETRO_ACTION_NOT_FOUND")
+	}
+
+	return nil
+}
+
+// ConfirmRetroActionAssignee confirms the user can be assigned to the retros actions,
+// either by having joined the retro or being a member of the retros team
+func (d *Database) ConfirmRetroActionAssignee(RetroID string, UserID string) error {
+	var assignable bool
+
+	if err := d.db.QueryRow(
+		`SELECT EXISTS (
+			SELECT 1 FROM retro_user WHERE retro_id = $1 AND user_id = $2
+		) OR EXISTS (
+			SELECT 1 FROM team_retro tr
+			JOIN team_user tu ON tu.team_id = tr.team_id
+			WHERE tr.retro_id = $1 AND tu.user_id = $2
+		);`,
+		RetroID,
+		UserID,
+	).Scan(&assignable); err != nil {
+		d.logger.Error("confirm retro action assignee query error", zap.Error(err))
+		return errors.New("ASSIGNEE_NOT_RETRO_MEMBER")
+	}
+	if !assignable {
+		return errors.New("ASSIGNEE_NOT_RETRO_MEMBER")
+	}
+
+	return nil
+}
+
+// ConfirmRetroActionCommentAuthor confirms the user wrote the retro action comment
+func (d *Database) ConfirmRetroActionCommentAuthor(ActionID string, CommentID string, UserID string) error {
+	var commentId string
+
+	if err := d.db.QueryRow(
+		`SELECT id FROM retro_action_comment WHERE id = $1 AND action_id = $2 AND user_id = $3;`,
+		CommentID,
+		ActionID,
+		UserID,
+	).Scan(&commentId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm retro action comment author query error", zap.Error(err))
+		}
+		return errors.New("REQUIRES_COMMENT_AUTHOR")
+	}
+
+	return nil
+}
+
 // RetroActionCommentAdd adds a comment to a retro action
 func (d *Database) RetroActionCommentAdd(RetroID string, ActionID string, UserID string, Comment string) ([]*model.RetroAction, error) {
 	if _, err := d.db.Exec(
@@ -198,3 +365,56 @@ func (d *Database) RetroActionAssigneeDelete(RetroID string, ActionID string, Us
 
 	return actions, nil
 }
+
+// GetOverdueRetroActionReminders gets each assignee of the incomplete retro actions past their due date
+// that hasn't been reminded about the actions current due date yet
+func (d *Database) GetOverdueRetroActionReminders() ([]*model.RetroActionReminder, error) {
+	var Reminders = make([]*model.RetroActionReminder, 0)
+
+	rows, err := d.db.Query(
+		`SELECT u.id, u.name, u.email, ra.id, ra.content, ra.due_date, r.id, r.name
+		FROM retro_action ra
+		JOIN retro r ON r.id = ra.retro_id
+		JOIN retro_action_assignee raa ON raa.action_id = ra.id
+		JOIN users u ON u.id = raa.user_id
+		WHERE ra.completed = false AND ra.due_date < CURRENT_DATE
+			AND raa.overdue_reminded_due_date IS DISTINCT FROM ra.due_date
+			AND u.email IS NOT NULL AND u.email <> ''
+		ORDER BY u.email, ra.due_date;`,
+	)
+	if err != nil {
+		d.logger.Error("get overdue retro actions query error", zap.Error(err))
+		return nil, errors.New("error getting overdue retro actions")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r = &model.RetroActionReminder{}
+		if err := rows.Scan(
+			&r.UserID, &r.UserName, &r.UserEmail, &r.ActionID, &r.Content, &r.DueDate, &r.RetroID, &r.RetroName,
+		); err != nil {
+			d.logger.Error("overdue retro action row scan error", zap.Error(err))
+			continue
+		}
+		Reminders = append(Reminders, r)
+	}
+
+	return Reminders, nil
+}
+
+// SetRetroActionsReminded marks the overdue reminders as sent to their assignee for the actions current due date
+func (d *Database) SetRetroActionsReminded(Reminders []*model.RetroActionReminder) error {
+	reminders, _ := json.Marshal(Reminders)
+
+	if _, err := d.db.Exec(
+		`UPDATE retro_action_assignee raa SET overdue_reminded_due_date = ra.due_date
+		FROM retro_action ra, jsonb_to_recordset($1::jsonb) AS r("actionId" UUID, "userId" UUID)
+		WHERE raa.action_id = r."actionId" AND raa.user_id = r."userId" AND ra.id = raa.action_id;`,
+		string(reminders),
+	); err != nil {
+		d.logger.Error("set retro actions reminded query error", zap.Error(err))
+		return errors.New("error setting retro actions reminded")
+	}
+
+	return nil
+}
@@ -18,6 +18,19 @@ func (d *Database) FilterItemsByUser(UserID string, Items []*model.RetroItem) []
 	return filteredItems
 }
 
+// FilterVotesByUser filters the list of votes by userId
+func (d *Database) FilterVotesByUser(UserID string, Votes []*model.RetroVote) []*model.RetroVote {
+	filteredVotes := make([]*model.RetroVote, 0)
+
+	for _, vote := range Votes {
+		if vote.UserID == UserID {
+			filteredVotes = append(filteredVotes, vote)
+		}
+	}
+
+	return filteredVotes
+}
+
 // CreateRetroItem adds a feedback item to the retro
 func (d *Database) CreateRetroItem(RetroID string, UserID string, ItemType string, Content string) ([]*model.RetroItem, error) {
 	var groupId string
@@ -141,14 +154,14 @@ func (d *Database) GetRetroVotes(RetroID string) []*model.RetroVote {
 	var votes = make([]*model.RetroVote, 0)
 
 	itemRows, itemsErr := d.db.Query(
-		`SELECT group_id, user_id FROM retro_group_vote WHERE retro_id = $1;`,
+		`SELECT group_id, user_id, count FROM retro_group_vote WHERE retro_id = $1;`,
 		RetroID,
 	)
 	if itemsErr == nil {
 		defer itemRows.Close()
 		for itemRows.Next() {
 			var rv = &model.RetroVote{}
-			if err := itemRows.Scan(&rv.GroupID, &rv.UserID); err != nil {
+			if err := itemRows.Scan(&rv.GroupID, &rv.UserID, &rv.Count); err != nil {
 				d.logger.Error("get retro votes query scan error", zap.Error(err))
 			} else {
 				votes = append(votes, rv)
@@ -161,12 +174,19 @@ func (d *Database) GetRetroVotes(RetroID string) []*model.RetroVote {
 	return votes
 }
 
-// GroupUserVote inserts a user vote for the retro item group
-func (d *Database) GroupUserVote(RetroID string, GroupID string, UserID string) ([]*model.RetroVote, error) {
+// GroupUserVote inserts a user vote for the retro item group,
+// with cumulative voting a repeat vote for the group adds to the users vote count instead
+func (d *Database) GroupUserVote(RetroID string, GroupID string, UserID string, Cumulative bool) ([]*model.RetroVote, error) {
+	var onConflict = `DO NOTHING`
+	if Cumulative {
+		onConflict = `DO UPDATE SET count = retro_group_vote.count + 1`
+	}
+
 	if _, err := d.db.Exec(
 		`INSERT INTO retro_group_vote
 		(retro_id, group_id, user_id)
-		VALUES ($1, $2, $3);`,
+		VALUES ($1, $2, $3)
+		ON CONFLICT (retro_id, user_id, group_id) `+onConflict+`;`,
 		RetroID, GroupID, UserID,
 	); err != nil {
 		d.logger.Error("retro group vote query error", zap.Error(err))
@@ -177,10 +197,17 @@ func (d *Database) GroupUserVote(RetroID string, GroupID string, UserID string)
 	return votes, nil
 }
 
-// GroupUserSubtractVote deletes a user vote for the retro item group
+// GroupUserSubtractVote removes one of the users votes for the retro item group, deleting it when it was their last
 func (d *Database) GroupUserSubtractVote(RetroID string, GroupID string, UserID string) ([]*model.RetroVote, error) {
 	if _, err := d.db.Exec(
 		`DELETE FROM retro_group_vote
+		WHERE retro_id = $1 AND group_id = $2 AND user_id = $3 AND count <= 1;`,
+		RetroID, GroupID, UserID,
+	); err != nil {
+		d.logger.Error("retro group subtract vote query error", zap.Error(err))
+	}
+	if _, err := d.db.Exec(
+		`UPDATE retro_group_vote SET count = count - 1
 		WHERE retro_id = $1 AND group_id = $2 AND user_id = $3;`,
 		RetroID, GroupID, UserID,
 	); err != nil {
@@ -197,7 +224,7 @@ func (d *Database) RetroUserVoteCount(RetroID string, UserID string) (int, error
 	var voteCount int
 
 	err := d.db.QueryRow(
-		`SELECT count(group_id) FROM retro_group_vote WHERE retro_id = $1 AND user_id = $2;`,
+		`SELECT COALESCE(SUM(count), 0) FROM retro_group_vote WHERE retro_id = $1 AND user_id = $2;`,
 		RetroID,
 		UserID,
 	).Scan(&voteCount)
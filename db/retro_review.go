@@ -0,0 +1,96 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// RetroCarryOverActions carries every incomplete action from the teams previous retros into the retros review
+func (d *Database) RetroCarryOverActions(TeamID string, RetroID string) error {
+	if _, err := d.db.Exec(
+		`INSERT INTO retro_action_review (retro_id, action_id, prior_status)
+		SELECT $2, ra.id, ra.status
+		FROM retro_action ra
+		JOIN team_retro tr ON tr.retro_id = ra.retro_id
+		WHERE tr.team_id = $1 AND ra.retro_id <> $2 AND ra.completed = false
+		ON CONFLICT DO NOTHING;`,
+		TeamID,
+		RetroID,
+	); err != nil {
+		d.logger.Error("carry over retro actions query error", zap.Error(err))
+		return errors.New("error carrying over retro actions")
+	}
+
+	return nil
+}
+
+// GetRetroReviewActions retrieves the actions carried over into the retro for review along with their decision
+func (d *Database) GetRetroReviewActions(RetroID string) []*model.RetroReviewAction {
+	var actions = make([]*model.RetroReviewAction, 0)
+
+	rows, err := d.db.Query(
+		`SELECT ra.id, ra.retro_id, r.name, ra.content, ra.completed, ra.status, ra.priority, ra.due_date,
+			COALESCE(rar.decision, ''), `+retroActionAssigneesComments+`
+		FROM retro_action_review rar
+		JOIN retro_action ra ON ra.id = rar.action_id
+		JOIN retro r ON r.id = ra.retro_id
+		WHERE rar.retro_id = $1
+		ORDER BY ra.created_date ASC;`,
+		RetroID,
+	)
+	if err != nil {
+		d.logger.Error("get retro review actions query error", zap.Error(err))
+		return actions
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ri = &model.RetroReviewAction{
+			Action: &model.RetroAction{},
+		}
+		var assignees string
+		var comments string
+		if err := rows.Scan(
+			&ri.Action.ID, &ri.Action.RetroID, &ri.RetroName, &ri.Action.Content, &ri.Action.Completed,
+			&ri.Action.Status, &ri.Action.Priority, &ri.Action.DueDate, &ri.Decision, &assignees, &comments,
+		); err != nil {
+			d.logger.Error("get retro review actions scan error", zap.Error(err))
+			continue
+		}
+		d.scanRetroActionAssigneesComments(ri.Action, assignees, comments)
+		actions = append(actions, ri)
+	}
+
+	return actions
+}
+
+// RetroReviewAction sets the review decision of a carried over action, closing the action sets it done,
+// dropping it sets it dropped and keeping it restores the status it had when carried over
+func (d *Database) RetroReviewAction(RetroID string, ActionID string, Decision string) ([]*model.RetroReviewAction, error) {
+	result, err := d.db.Exec(
+		`WITH review AS (
+			UPDATE retro_action_review SET decision = $3, updated_date = NOW()
+			WHERE retro_id = $1 AND action_id = $2
+			RETURNING action_id, prior_status
+		)
+		UPDATE retro_action ra SET
+			status = CASE $3::TEXT WHEN 'close' THEN 'done' WHEN 'drop' THEN 'dropped' ELSE review.prior_status END,
+			completed = $3::TEXT <> 'keep',
+			updated_date = NOW()
+		FROM review WHERE ra.id = review.action_id;`,
+		RetroID,
+		ActionID,
+		Decision,
+	)
+	if err != nil {
+		d.logger.Error("review retro action query error", zap.Error(err))
+		return nil, errors.New("error reviewing retro action")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, errors.New("RETRO_ACTION_NOT_FOUND")
+	}
+
+	return d.GetRetroReviewActions(RetroID), nil
+}
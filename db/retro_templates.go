@@ -0,0 +1,173 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// retroFormatColumns the columns of the built-in retro formats
+var retroFormatColumns = map[string][]*model.RetroColumn{
+	"worked_improve_question": {
+		{Key: "worked", Name: "Went Well", Color: "green", Icon: "smiley"},
+		{Key: "improve", Name: "Needs Improvement", Color: "red", Icon: "frown"},
+		{Key: "question", Name: "Question", Color: "blue", Icon: "question"},
+	},
+	"start_stop_continue": {
+		{Key: "start", Name: "Start", Color: "green"},
+		{Key: "stop", Name: "Stop", Color: "red"},
+		{Key: "continue", Name: "Continue", Color: "blue"},
+	},
+}
+
+const retroTemplateSelect = `SELECT
+		rt.id, rt.name, COALESCE(rt.user_id::TEXT, ''), COALESCE(rt.team_id::TEXT, ''),
+		rt.columns, rt.created_date, rt.updated_date
+		FROM retro_template rt`
+
+// scanRetroTemplate scans a retro template row selected by retroTemplateSelect
+func (d *Database) scanRetroTemplate(row interface{ Scan(...interface{}) error }) (*model.RetroTemplate, error) {
+	var t = &model.RetroTemplate{
+		Columns: make([]*model.RetroColumn, 0),
+	}
+	var columns string
+
+	if err := row.Scan(
+		&t.Id,
+		&t.Name,
+		&t.UserId,
+		&t.TeamId,
+		&columns,
+		&t.CreatedDate,
+		&t.UpdatedDate,
+	); err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal([]byte(columns), &t.Columns)
+
+	return t, nil
+}
+
+// retroTemplateList gets a list of retro templates matching the where clause
+func (d *Database) retroTemplateList(Where string, Args ...interface{}) ([]*model.RetroTemplate, error) {
+	var Templates = make([]*model.RetroTemplate, 0)
+
+	rows, err := d.db.Query(retroTemplateSelect+` `+Where+` ORDER BY rt.name;`, Args...)
+	if err != nil {
+		d.logger.Error("get retro templates query error", zap.Error(err))
+		return nil, errors.New("error getting retro templates")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := d.scanRetroTemplate(rows)
+		if err != nil {
+			d.logger.Error("retro template row scan error", zap.Error(err))
+			continue
+		}
+		Templates = append(Templates, t)
+	}
+
+	return Templates, nil
+}
+
+// RetroTemplateListByUser gets a list of the users personal retro templates
+func (d *Database) RetroTemplateListByUser(UserID string) ([]*model.RetroTemplate, error) {
+	return d.retroTemplateList(`WHERE rt.user_id = $1 AND rt.team_id IS NULL`, UserID)
+}
+
+// RetroTemplateListByTeam gets a list of the teams retro templates
+func (d *Database) RetroTemplateListByTeam(TeamID string) ([]*model.RetroTemplate, error) {
+	return d.retroTemplateList(`WHERE rt.team_id = $1`, TeamID)
+}
+
+// RetroTemplateGet gets a retro template by ID
+func (d *Database) RetroTemplateGet(TemplateID string) (*model.RetroTemplate, error) {
+	t, err := d.scanRetroTemplate(d.db.QueryRow(retroTemplateSelect+` WHERE rt.id = $1;`, TemplateID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get retro template query error", zap.Error(err))
+		}
+		return nil, errors.New("RETRO_TEMPLATE_NOT_FOUND")
+	}
+
+	return t, nil
+}
+
+// RetroTemplateCreate creates a retro template owned by either a user or a team
+func (d *Database) RetroTemplateCreate(UserID string, TeamID string, Name string, Columns []*model.RetroColumn) (*model.RetroTemplate, error) {
+	var TemplateID string
+	var columnsJSON, _ = json.Marshal(Columns)
+
+	if err := d.db.QueryRow(
+		`INSERT INTO retro_template (user_id, team_id, name, columns)
+		VALUES (NULLIF($1, '')::UUID, NULLIF($2, '')::UUID, $3, $4)
+		RETURNING id;`,
+		UserID,
+		TeamID,
+		Name,
+		string(columnsJSON),
+	).Scan(&TemplateID); err != nil {
+		d.logger.Error("create retro template query error", zap.Error(err))
+		return nil, errors.New("error creating retro template")
+	}
+
+	return d.RetroTemplateGet(TemplateID)
+}
+
+// RetroTemplateDelete deletes a retro template
+func (d *Database) RetroTemplateDelete(TemplateID string) error {
+	if _, err := d.db.Exec(
+		`DELETE FROM retro_template WHERE id = $1;`,
+		TemplateID,
+	); err != nil {
+		d.logger.Error("delete retro template query error", zap.Error(err))
+		return errors.New("error deleting retro template")
+	}
+
+	return nil
+}
+
+// ConfirmRetroTemplateAccess confirms the user owns the retro template or is a member of the team that does
+func (d *Database) ConfirmRetroTemplateAccess(TemplateID string, UserID string) error {
+	var templateId string
+
+	if err := d.db.QueryRow(`SELECT rt.id
+		FROM retro_template rt
+		LEFT JOIN team_user tu ON tu.team_id = rt.team_id AND tu.user_id = $2
+		WHERE rt.id = $1 AND ((rt.team_id IS NULL AND rt.user_id = $2) OR tu.user_id IS NOT NULL);`,
+		TemplateID,
+		UserID,
+	).Scan(&templateId); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("confirm retro template access query error", zap.Error(err))
+		}
+		return errors.New("RETRO_TEMPLATE_NOT_FOUND")
+	}
+
+	return nil
+}
+
+// GetRetroColumns gets the columns of the retros format
+func (d *Database) GetRetroColumns(RetroID string) ([]*model.RetroColumn, error) {
+	var Columns = make([]*model.RetroColumn, 0)
+	var columns string
+
+	if err := d.db.QueryRow(
+		`SELECT columns FROM retro WHERE id = $1;`,
+		RetroID,
+	).Scan(&columns); err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get retro columns query error", zap.Error(err))
+		}
+		return nil, errors.New("RETRO_NOT_FOUND")
+	}
+
+	_ = json.Unmarshal([]byte(columns), &Columns)
+
+	return Columns, nil
+}
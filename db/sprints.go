@@ -0,0 +1,308 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+const sprintSelect = `SELECT id, team_id, name, start_date, end_date, capacity, created_date, updated_date
+		FROM team_sprint`
+
+// scanSprint scans a sprint row selected by sprintSelect
+func (d *Database) scanSprint(row interface{ Scan(...interface{}) error }) (*model.Sprint, error) {
+	var s = &model.Sprint{}
+
+	if err := row.Scan(
+		&s.Id,
+		&s.TeamId,
+		&s.Name,
+		&s.StartDate,
+		&s.EndDate,
+		&s.Capacity,
+		&s.CreatedDate,
+		&s.UpdatedDate,
+	); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// sprintPlans sets the plans committed to the sprint along with the committed and done points
+func (d *Database) sprintPlans(Sprint *model.Sprint) error {
+	Sprint.Plans = make([]*model.SprintPlan, 0)
+
+	rows, err := d.db.Query(
+		`SELECT p.id, p.battle_id, p.name, COALESCE(p.reference_id, ''), p.points, sp.done, sp.done_date, sp.committed_date
+		FROM team_sprint_plan sp
+		JOIN plans p ON p.id = sp.plan_id
+		WHERE sp.sprint_id = $1
+		ORDER BY sp.committed_date;`,
+		Sprint.Id,
+	)
+	if err != nil {
+		d.logger.Error("get sprint plans query error", zap.Error(err))
+		return errors.New("error getting sprint plans")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sp model.SprintPlan
+		var DoneDate sql.NullTime
+		if err := rows.Scan(
+			&sp.PlanId,
+			&sp.BattleId,
+			&sp.Name,
+			&sp.ReferenceId,
+			&sp.Points,
+			&sp.Done,
+			&DoneDate,
+			&sp.CommittedDate,
+		); err != nil {
+			d.logger.Error("sprint plan row scan error", zap.Error(err))
+			continue
+		}
+		if DoneDate.Valid {
+			sp.DoneDate = &DoneDate.Time
+		}
+		if points, ok := voteNumericValue(sp.Points, nil); ok {
+			Sprint.CommittedPoints += points
+			if sp.Done {
+				Sprint.DonePoints += points
+			}
+		}
+		Sprint.Plans = append(Sprint.Plans, &sp)
+	}
+
+	return nil
+}
+
+// SprintListByTeam gets a list of the teams sprints, most recent first
+func (d *Database) SprintListByTeam(TeamID string) ([]*model.Sprint, error) {
+	var Sprints = make([]*model.Sprint, 0)
+
+	rows, err := d.db.Query(sprintSelect+` WHERE team_id = $1 ORDER BY start_date DESC;`, TeamID)
+	if err != nil {
+		d.logger.Error("get team sprints query error", zap.Error(err))
+		return nil, errors.New("error getting team sprints")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := d.scanSprint(rows)
+		if err != nil {
+			d.logger.Error("sprint row scan error", zap.Error(err))
+			continue
+		}
+		Sprints = append(Sprints, s)
+	}
+
+	for _, s := range Sprints {
+		if err := d.sprintPlans(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return Sprints, nil
+}
+
+// SprintGet gets the teams sprint by ID with its committed plans
+func (d *Database) SprintGet(TeamID string, SprintID string) (*model.Sprint, error) {
+	s, err := d.scanSprint(d.db.QueryRow(sprintSelect+` WHERE id = $1 AND team_id = $2;`, SprintID, TeamID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			d.logger.Error("get sprint query error", zap.Error(err))
+		}
+		return nil, errors.New("SPRINT_NOT_FOUND")
+	}
+
+	if err := d.sprintPlans(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SprintCreate creates a sprint for the team
+func (d *Database) SprintCreate(TeamID string, Name string, StartDate time.Time, EndDate time.Time, Capacity float64) (*model.Sprint, error) {
+	var SprintID string
+
+	if err := d.db.QueryRow(
+		`INSERT INTO team_sprint (team_id, name, start_date, end_date, capacity)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id;`,
+		TeamID,
+		Name,
+		StartDate,
+		EndDate,
+		Capacity,
+	).Scan(&SprintID); err != nil {
+		d.logger.Error("create sprint query error", zap.Error(err))
+		return nil, errors.New("error creating sprint")
+	}
+
+	return d.SprintGet(TeamID, SprintID)
+}
+
+// SprintUpdate updates the teams sprint
+func (d *Database) SprintUpdate(TeamID string, SprintID string, Name string, StartDate time.Time, EndDate time.Time, Capacity float64) (*model.Sprint, error) {
+	res, err := d.db.Exec(
+		`UPDATE team_sprint SET name = $3, start_date = $4, end_date = $5, capacity = $6, updated_date = NOW()
+		WHERE id = $1 AND team_id = $2;`,
+		SprintID,
+		TeamID,
+		Name,
+		StartDate,
+		EndDate,
+		Capacity,
+	)
+	if err != nil {
+		d.logger.Error("update sprint query error", zap.Error(err))
+		return nil, errors.New("error updating sprint")
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return nil, errors.New("SPRINT_NOT_FOUND")
+	}
+
+	return d.SprintGet(TeamID, SprintID)
+}
+
+// SprintDelete deletes the teams sprint
+func (d *Database) SprintDelete(TeamID string, SprintID string) error {
+	res, err := d.db.Exec(
+		`DELETE FROM team_sprint WHERE id = $1 AND team_id = $2;`,
+		SprintID,
+		TeamID,
+	)
+	if err != nil {
+		d.logger.Error("delete sprint query error", zap.Error(err))
+		return errors.New("error deleting sprint")
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errors.New("SPRINT_NOT_FOUND")
+	}
+
+	return nil
+}
+
+// SprintCommitPlan commits a plan from one of the teams battles to the sprint
+func (d *Database) SprintCommitPlan(TeamID string, SprintID string, PlanID string) (*model.Sprint, error) {
+	res, err := d.db.Exec(
+		`INSERT INTO team_sprint_plan (sprint_id, plan_id)
+		SELECT s.id, p.id
+		FROM team_sprint s
+		JOIN team_battle tb ON tb.team_id = s.team_id
+		JOIN plans p ON p.battle_id = tb.battle_id
+		WHERE s.id = $1 AND s.team_id = $2 AND p.id = $3
+		ON CONFLICT (sprint_id, plan_id) DO NOTHING;`,
+		SprintID,
+		TeamID,
+		PlanID,
+	)
+	if err != nil {
+		d.logger.Error("sprint commit plan query error", zap.Error(err))
+		return nil, errors.New("error committing plan to sprint")
+	}
+	Sprint, err := d.SprintGet(TeamID, SprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing inserted and not already committed means the plan isn't in the teams battles
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		var committed bool
+		for _, p := range Sprint.Plans {
+			committed = committed || p.PlanId == PlanID
+		}
+		if !committed {
+			return nil, errors.New("PLAN_NOT_FOUND")
+		}
+	}
+
+	return Sprint, nil
+}
+
+// SprintUncommitPlan removes a committed plan from the sprint
+func (d *Database) SprintUncommitPlan(TeamID string, SprintID string, PlanID string) (*model.Sprint, error) {
+	if _, err := d.db.Exec(
+		`DELETE FROM team_sprint_plan sp
+		USING team_sprint s
+		WHERE sp.sprint_id = s.id AND s.id = $1 AND s.team_id = $2 AND sp.plan_id = $3;`,
+		SprintID,
+		TeamID,
+		PlanID,
+	); err != nil {
+		d.logger.Error("sprint uncommit plan query error", zap.Error(err))
+		return nil, errors.New("error uncommitting plan from sprint")
+	}
+
+	return d.SprintGet(TeamID, SprintID)
+}
+
+// SprintSetPlanDone sets whether the sprints committed plan is done
+func (d *Database) SprintSetPlanDone(TeamID string, SprintID string, PlanID string, Done bool) (*model.Sprint, error) {
+	res, err := d.db.Exec(
+		`UPDATE team_sprint_plan sp
+		SET done = $4, done_date = CASE WHEN $4 THEN COALESCE(sp.done_date, NOW()) ELSE NULL END
+		FROM team_sprint s
+		WHERE sp.sprint_id = s.id AND s.id = $1 AND s.team_id = $2 AND sp.plan_id = $3;`,
+		SprintID,
+		TeamID,
+		PlanID,
+		Done,
+	)
+	if err != nil {
+		d.logger.Error("sprint set plan done query error", zap.Error(err))
+		return nil, errors.New("error updating sprint plan")
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return nil, errors.New("SPRINT_PLAN_NOT_FOUND")
+	}
+
+	return d.SprintGet(TeamID, SprintID)
+}
+
+// SprintBurnup gets the sprints burn-up of committed scope and completed points per day
+func (d *Database) SprintBurnup(TeamID string, SprintID string) (*model.SprintBurnup, error) {
+	Sprint, err := d.SprintGet(TeamID, SprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SprintBurnup{
+		SprintId: Sprint.Id,
+		Capacity: Sprint.Capacity,
+		Days:     calculateSprintBurnup(Sprint, time.Now()),
+	}, nil
+}
+
+// calculateSprintBurnup gets the sprints committed scope and completed points at the end of each day
+// from the sprint start through its end or Now if sooner, only finalized numeric points are counted
+func calculateSprintBurnup(Sprint *model.Sprint, Now time.Time) []*model.SprintBurnupDay {
+	var Days = make([]*model.SprintBurnupDay, 0)
+
+	start := time.Date(Sprint.StartDate.Year(), Sprint.StartDate.Month(), Sprint.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(Sprint.EndDate.Year(), Sprint.EndDate.Month(), Sprint.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	for day := start; !day.After(end) && day.Before(Now); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		burnupDay := &model.SprintBurnupDay{Date: day}
+
+		for _, p := range Sprint.Plans {
+			points, ok := voteNumericValue(p.Points, nil)
+			if !ok || !p.CommittedDate.Before(dayEnd) {
+				continue
+			}
+			burnupDay.Scope += points
+			if p.Done && p.DoneDate != nil && p.DoneDate.Before(dayEnd) {
+				burnupDay.Completed += points
+			}
+		}
+
+		Days = append(Days, burnupDay)
+	}
+
+	return Days
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+)
+
+// TestCalculateSprintBurnup calls calculateSprintBurnup and makes sure scope grows as plans are committed,
+// completed points follow the done dates and days after now are left out
+func TestCalculateSprintBurnup(t *testing.T) {
+	Day := func(d int, h int) time.Time { return time.Date(2022, time.July, d, h, 0, 0, 0, time.UTC) }
+	Done := Day(19, 16)
+	Sprint := &model.Sprint{
+		StartDate: Day(18, 0),
+		EndDate:   Day(29, 0),
+		Plans: []*model.SprintPlan{
+			{Points: "5", CommittedDate: Day(17, 9), Done: true, DoneDate: &Done},
+			{Points: "3", CommittedDate: Day(19, 9)},
+			{Points: "?", CommittedDate: Day(17, 9)},
+		},
+	}
+
+	Days := calculateSprintBurnup(Sprint, Day(20, 12))
+
+	if len(Days) != 3 {
+		t.Fatalf(`expected Days length: %d to match 3`, len(Days))
+	}
+	if Days[0].Scope != 5 || Days[0].Completed != 0 {
+		t.Fatalf(`expected first day scope 5 and completed 0, got %v and %v`, Days[0].Scope, Days[0].Completed)
+	}
+	if Days[1].Scope != 8 || Days[1].Completed != 5 {
+		t.Fatalf(`expected second day scope 8 and completed 5, got %v and %v`, Days[1].Scope, Days[1].Completed)
+	}
+}
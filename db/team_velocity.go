@@ -0,0 +1,167 @@
+package db
+
+import (
+	"errors"
+	"math"
+	mrand "math/rand"
+	"sort"
+	"time"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"go.uber.org/zap"
+)
+
+// velocityForecastTrials the number of Monte Carlo trials run for a velocity forecast
+const velocityForecastTrials = 10000
+
+// velocityForecastHorizon the most periods a forecast trial simulates before it's reported beyond the horizon
+const velocityForecastHorizon = 520
+
+// velocityForecastConfidences the confidence levels reported by a velocity forecast
+var velocityForecastConfidences = []int{50, 85, 95}
+
+// finalizedPlan the points of a plan and when it was finalized
+type finalizedPlan struct {
+	Points      string
+	FinalizedAt time.Time
+}
+
+// TeamVelocity gets the points finalized in the teams battles for the most recent complete periods
+// of PeriodWeeks weeks, when ForecastPoints is above 0 the weeks to finish them are forecast from those periods
+func (d *Database) TeamVelocity(TeamID string, PeriodWeeks int, PeriodCount int, ForecastPoints float64) (*model.TeamVelocity, error) {
+	var Plans = make([]*finalizedPlan, 0)
+
+	rows, err := d.db.Query(
+		`SELECT p.points, p.voteend_time
+		FROM plans p
+		JOIN team_battle tb ON tb.battle_id = p.battle_id
+		WHERE tb.team_id = $1 AND p.points <> '' AND p.skipped = false;`,
+		TeamID,
+	)
+	if err != nil {
+		d.logger.Error("get team finalized plans query error", zap.Error(err))
+		return nil, errors.New("error getting team velocity")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p finalizedPlan
+		if err := rows.Scan(&p.Points, &p.FinalizedAt); err != nil {
+			d.logger.Error("team finalized plans row scan error", zap.Error(err))
+			continue
+		}
+		Plans = append(Plans, &p)
+	}
+
+	Velocity := &model.TeamVelocity{
+		PeriodWeeks: PeriodWeeks,
+		Periods:     calculateVelocityPeriods(Plans, PeriodWeeks, PeriodCount, time.Now()),
+	}
+
+	var velocities = make([]float64, 0, len(Velocity.Periods))
+	var total runningAverage
+	for _, p := range Velocity.Periods {
+		velocities = append(velocities, p.Points)
+		total.add(p.Points)
+	}
+	Velocity.AveragePoints = total.value()
+
+	if ForecastPoints > 0 {
+		Percentiles, err := forecastPeriods(velocities, ForecastPoints, velocityForecastTrials, velocityForecastConfidences, time.Now().UnixNano())
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range Percentiles {
+			p.Weeks = p.Periods * PeriodWeeks
+		}
+		Velocity.Forecast = &model.VelocityForecast{
+			Points:      ForecastPoints,
+			Trials:      velocityForecastTrials,
+			Percentiles: Percentiles,
+		}
+	}
+
+	return Velocity, nil
+}
+
+// calculateVelocityPeriods sums the numeric points finalized in each of the most recent complete periods
+// of PeriodWeeks weeks before Now, periods start on Mondays (UTC) and empty periods are kept
+func calculateVelocityPeriods(Plans []*finalizedPlan, PeriodWeeks int, PeriodCount int, Now time.Time) []*model.VelocityPeriod {
+	var Periods = make([]*model.VelocityPeriod, 0, PeriodCount)
+	var periodLength = time.Duration(PeriodWeeks) * 7 * 24 * time.Hour
+
+	day := time.Date(Now.Year(), Now.Month(), Now.Day(), 0, 0, 0, 0, time.UTC)
+	currentWeek := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	end := currentWeek
+	for i := 0; i < PeriodCount; i++ {
+		start := end.Add(-periodLength)
+		Periods = append([]*model.VelocityPeriod{{StartDate: start, EndDate: end}}, Periods...)
+		end = start
+	}
+
+	for _, p := range Plans {
+		points, ok := voteNumericValue(p.Points, nil)
+		if !ok {
+			continue
+		}
+		for _, period := range Periods {
+			if !p.FinalizedAt.Before(period.StartDate) && p.FinalizedAt.Before(period.EndDate) {
+				period.Points += points
+				period.PlanCount++
+				break
+			}
+		}
+	}
+
+	return Periods
+}
+
+// forecastPeriods runs a Monte Carlo simulation sampling the historical period velocities
+// to find how many periods finishing the points takes at each confidence level, trials are
+// simulated for at most velocityForecastHorizon periods and points that can't be finished within it are rejected
+func forecastPeriods(Velocities []float64, Points float64, Trials int, Confidences []int, Seed int64) ([]*model.ForecastPercentile, error) {
+	if math.IsNaN(Points) || math.IsInf(Points, 0) || Points <= 0 {
+		return nil, errors.New("INVALID_FORECAST_POINTS")
+	}
+
+	var maxVelocity float64
+	for _, v := range Velocities {
+		maxVelocity = math.Max(maxVelocity, v)
+	}
+	if maxVelocity <= 0 {
+		return nil, errors.New("NOT_ENOUGH_VELOCITY_HISTORY")
+	}
+	if Points > maxVelocity*velocityForecastHorizon {
+		return nil, errors.New("FORECAST_BEYOND_HORIZON")
+	}
+
+	rnd := mrand.New(mrand.NewSource(Seed))
+	outcomes := make([]int, Trials)
+	for t := range outcomes {
+		var done float64
+		var periods int
+		for done < Points && periods <= velocityForecastHorizon {
+			done += Velocities[rnd.Intn(len(Velocities))]
+			periods++
+		}
+		outcomes[t] = periods
+	}
+	sort.Ints(outcomes)
+
+	var Percentiles = make([]*model.ForecastPercentile, 0, len(Confidences))
+	for _, c := range Confidences {
+		i := int(math.Ceil(float64(c)/100*float64(Trials))) - 1
+		if i < 0 {
+			i = 0
+		}
+		if outcomes[i] > velocityForecastHorizon {
+			Percentiles = append(Percentiles, &model.ForecastPercentile{
+				Confidence: c, Periods: velocityForecastHorizon, BeyondHorizon: true,
+			})
+			continue
+		}
+		Percentiles = append(Percentiles, &model.ForecastPercentile{Confidence: c, Periods: outcomes[i]})
+	}
+
+	return Percentiles, nil
+}
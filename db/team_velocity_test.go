@@ -0,0 +1,66 @@
+package db
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestCalculateVelocityPeriods calls calculateVelocityPeriods and makes sure only numeric points
+// finalized in the complete periods before now are summed
+func TestCalculateVelocityPeriods(t *testing.T) {
+	Now := time.Date(2022, time.July, 20, 15, 0, 0, 0, time.UTC)
+	Plans := []*finalizedPlan{
+		{Points: "5", FinalizedAt: time.Date(2022, time.July, 12, 9, 0, 0, 0, time.UTC)},
+		{Points: "3", FinalizedAt: time.Date(2022, time.July, 17, 23, 0, 0, 0, time.UTC)},
+		{Points: "?", FinalizedAt: time.Date(2022, time.July, 13, 9, 0, 0, 0, time.UTC)},
+		{Points: "8", FinalizedAt: time.Date(2022, time.July, 19, 9, 0, 0, 0, time.UTC)},
+	}
+
+	Periods := calculateVelocityPeriods(Plans, 1, 2, Now)
+
+	if len(Periods) != 2 {
+		t.Fatalf(`expected Periods length: %d to match 2`, len(Periods))
+	}
+	if !Periods[1].StartDate.Equal(time.Date(2022, time.July, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf(`expected last period to start on Monday 2022-07-11, got %v`, Periods[1].StartDate)
+	}
+	if Periods[0].Points != 0 || Periods[1].Points != 8 || Periods[1].PlanCount != 2 {
+		t.Fatalf(`expected period points 0 and 8, got %v and %v`, Periods[0].Points, Periods[1].Points)
+	}
+}
+
+// TestForecastPeriods calls forecastPeriods and makes sure a steady velocity forecasts
+// the same number of periods at every confidence, no velocity history and unbounded points are rejected
+// and trials running past the horizon are reported beyond it
+func TestForecastPeriods(t *testing.T) {
+	Percentiles, err := forecastPeriods([]float64{10, 10}, 25, 100, []int{50, 85, 95}, 1)
+	if err != nil {
+		t.Fatalf(`expected no error, got %v`, err)
+	}
+	for _, p := range Percentiles {
+		if p.Periods != 3 {
+			t.Fatalf(`expected %d%% confidence Periods: %d to match 3`, p.Confidence, p.Periods)
+		}
+	}
+
+	Percentiles, err = forecastPeriods([]float64{2, 20}, 40, 1000, []int{50, 95}, 1)
+	if err != nil || Percentiles[0].Periods > Percentiles[1].Periods {
+		t.Fatalf(`expected higher confidence to need at least as many periods, got %v`, err)
+	}
+
+	if _, err := forecastPeriods([]float64{0, 0}, 10, 100, []int{50}, 1); err == nil {
+		t.Fatalf(`expected no velocity history to be rejected`)
+	}
+
+	for _, Points := range []float64{math.Inf(1), math.NaN(), 10*velocityForecastHorizon + 1} {
+		if _, err := forecastPeriods([]float64{2, 10}, Points, 100, []int{50}, 1); err == nil {
+			t.Fatalf(`expected Points: %v to be rejected`, Points)
+		}
+	}
+
+	Percentiles, err = forecastPeriods([]float64{0, 1}, 400, 100, []int{95}, 1)
+	if err != nil || !Percentiles[0].BeyondHorizon || Percentiles[0].Periods != velocityForecastHorizon {
+		t.Fatalf(`expected the forecast to be beyond the horizon, got %+v %v`, Percentiles[0], err)
+	}
+}
@@ -0,0 +1,70 @@
+package email
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/matcornic/hermes/v2"
+	"go.uber.org/zap"
+)
+
+// SendAsyncVotingSummary sends the battle leader a summary of the plans voted on once async voting has closed
+func (m *Email) SendAsyncVotingSummary(UserName string, UserEmail string, BattleName string, BattleID string, Plans []*model.Plan) error {
+	var rows = make([][]hermes.Entry, 0, len(Plans))
+	for _, p := range Plans {
+		var VoteCount, Average, Spread string
+		if p.Results != nil {
+			VoteCount = strconv.Itoa(p.Results.VoteCount)
+			Average = strconv.FormatFloat(p.Results.Average, 'f', -1, 64)
+			Spread = fmt.Sprintf("%s - %s", p.Results.Min, p.Results.Max)
+			if p.Results.Consensus {
+				Spread = p.Results.Min
+			}
+		}
+		rows = append(rows, []hermes.Entry{
+			{Key: "Plan", Value: p.Name},
+			{Key: "Votes", Value: VoteCount},
+			{Key: "Average", Value: Average},
+			{Key: "Spread", Value: Spread},
+		})
+	}
+
+	emailBody, err := m.generateBody(
+		hermes.Body{
+			Name: UserName,
+			Intros: []string{
+				fmt.Sprintf("Async voting for %s has closed, the following plans are ready for their final points.", BattleName),
+			},
+			Table: hermes.Table{
+				Data: rows,
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "Review the results and finalize the plans in the battle.",
+					Button: hermes.Button{
+						Text: "Go to Battle",
+						Link: m.config.AppURL + "battle/" + BattleID,
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		m.logger.Error("Error Generating Async Voting Summary Email HTML", zap.Error(err))
+		return err
+	}
+
+	sendErr := m.Send(
+		UserName,
+		UserEmail,
+		fmt.Sprintf("Async voting closed for %s", BattleName),
+		emailBody,
+	)
+	if sendErr != nil {
+		m.logger.Error("Error sending Async Voting Summary Email", zap.Error(sendErr))
+		return sendErr
+	}
+
+	return nil
+}
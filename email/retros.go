@@ -0,0 +1,64 @@
+package email
+
+import (
+	"fmt"
+
+	"github.com/StevenWeathers/thunderdome-planning-poker/model"
+	"github.com/matcornic/hermes/v2"
+	"go.uber.org/zap"
+)
+
+// SendOverdueRetroActions reminds the assignee of their retro actions that are past their due date
+func (m *Email) SendOverdueRetroActions(UserName string, UserEmail string, Actions []*model.RetroActionReminder) error {
+	var rows = make([][]hermes.Entry, 0, len(Actions))
+	for _, a := range Actions {
+		rows = append(rows, []hermes.Entry{
+			{Key: "Action", Value: a.Content},
+			{Key: "Retro", Value: a.RetroName},
+			{Key: "Due", Value: a.DueDate.Format("Jan 2, 2006")},
+		})
+	}
+
+	var Link = m.config.AppURL
+	if len(Actions) == 1 {
+		Link = m.config.AppURL + "retro/" + Actions[0].RetroID
+	}
+
+	emailBody, err := m.generateBody(
+		hermes.Body{
+			Name: UserName,
+			Intros: []string{
+				"The following retro action items assigned to you are past their due date.",
+			},
+			Table: hermes.Table{
+				Data: rows,
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "Update their status or due date so your team knows where they stand.",
+					Button: hermes.Button{
+						Text: "Go to Thunderdome",
+						Link: Link,
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		m.logger.Error("Error Generating Overdue Retro Actions Email HTML", zap.Error(err))
+		return err
+	}
+
+	sendErr := m.Send(
+		UserName,
+		UserEmail,
+		fmt.Sprintf("You have %d overdue retro action items", len(Actions)),
+		emailBody,
+	)
+	if sendErr != nil {
+		m.logger.Error("Error sending Overdue Retro Actions Email", zap.Error(sendErr))
+		return sendErr
+	}
+
+	return nil
+}
@@ -12,45 +12,198 @@ type BattleUser struct {
 	Abandoned    bool   `json:"abandoned"`
 	Spectator    bool   `json:"spectator"`
 	GravatarHash string `json:"gravatarHash"`
+	BreakoutId   string `json:"breakoutId"`
 }
 
 // Battle aka arena
 type Battle struct {
-	Id                   string        `json:"id"`
-	Name                 string        `json:"name"`
-	Users                []*BattleUser `json:"users"`
-	Plans                []*Plan       `json:"plans"`
-	VotingLocked         bool          `json:"votingLocked"`
-	ActivePlanID         string        `json:"activePlanId"`
-	PointValuesAllowed   []string      `json:"pointValuesAllowed"`
-	AutoFinishVoting     bool          `json:"autoFinishVoting"`
-	Leaders              []string      `json:"leaders"`
-	PointAverageRounding string        `json:"pointAverageRounding"`
-	JoinCode             string        `json:"joinCode"`
-	LeaderCode           string        `json:"leaderCode,omitempty"`
-	CreatedDate          time.Time     `json:"createdDate"`
-	UpdatedDate          time.Time     `json:"updatedDate"`
+	Id                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	Users                []*BattleUser      `json:"users"`
+	Plans                []*Plan            `json:"plans"`
+	VotingLocked         bool               `json:"votingLocked"`
+	ActivePlanID         string             `json:"activePlanId"`
+	PointValuesAllowed   []string           `json:"pointValuesAllowed"`
+	AutoFinishVoting     bool               `json:"autoFinishVoting"`
+	Leaders              []string           `json:"leaders"`
+	PointAverageRounding string             `json:"pointAverageRounding"`
+	VotingTimerSeconds   int                `json:"votingTimerSeconds"`
+	EstimationScaleID    string             `json:"estimationScaleId"`
+	AnonymousVoting      bool               `json:"anonymousVoting"`
+	VotingMode           string             `json:"votingMode"`
+	VotingDeadline       *time.Time         `json:"votingDeadline"`
+	Dimensions           []*BattleDimension `json:"dimensions"`
+	DimensionFormula     string             `json:"dimensionFormula"`
+	Breakouts            []*BattleBreakout  `json:"breakouts"`
+	JoinCode             string             `json:"joinCode"`
+	LeaderCode           string             `json:"leaderCode,omitempty"`
+	CreatedDate          time.Time          `json:"createdDate"`
+	UpdatedDate          time.Time          `json:"updatedDate"`
+}
+
+// BattleDimension an estimation dimension (e.g. effort, risk, complexity) users vote on separately with its own values
+type BattleDimension struct {
+	Key                string   `json:"key"`
+	Name               string   `json:"name"`
+	EstimationScaleID  string   `json:"estimationScaleId"`
+	PointValuesAllowed []string `json:"pointValuesAllowed"`
+}
+
+// BattleTemplate reusable battle settings owned by either a user or a team
+type BattleTemplate struct {
+	Id                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	UserId               string             `json:"userId"`
+	TeamId               string             `json:"teamId"`
+	BattleName           string             `json:"battleName"`
+	PointValuesAllowed   []string           `json:"pointValuesAllowed"`
+	EstimationScaleID    string             `json:"estimationScaleId"`
+	AutoFinishVoting     bool               `json:"autoFinishVoting"`
+	PointAverageRounding string             `json:"pointAverageRounding"`
+	VotingTimerSeconds   int                `json:"votingTimerSeconds"`
+	AnonymousVoting      bool               `json:"anonymousVoting"`
+	VotingMode           string             `json:"votingMode"`
+	Dimensions           []*BattleDimension `json:"dimensions"`
+	DimensionFormula     string             `json:"dimensionFormula"`
+	Leaders              []string           `json:"leaders"`
+	CreatedDate          time.Time          `json:"createdDate"`
+	UpdatedDate          time.Time          `json:"updatedDate"`
+}
+
+// EstimationScaleValue a votable value of an estimation scale with an optional numeric weight used for averaging
+type EstimationScaleValue struct {
+	Value  string   `json:"value"`
+	Weight *float64 `json:"weight"`
+}
+
+// EstimationScale a named and ordered set of values (deck) users vote with
+type EstimationScale struct {
+	Id          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	UserId      string                  `json:"userId"`
+	TeamId      string                  `json:"teamId"`
+	IsDefault   bool                    `json:"isDefault"`
+	Values      []*EstimationScaleValue `json:"values"`
+	CreatedDate time.Time               `json:"createdDate"`
+	UpdatedDate time.Time               `json:"updatedDate"`
 }
 
 // Vote structure
 type Vote struct {
-	UserId    string `json:"warriorId"`
-	VoteValue string `json:"vote"`
+	UserId     string `json:"warriorId"`
+	VoteValue  string `json:"vote"`
+	Dimension  string `json:"dimension,omitempty"`
+	Confidence *int   `json:"confidence,omitempty"`
 }
 
 // Plan aka Story structure
 type Plan struct {
-	Id                 string    `json:"id"`
-	Name               string    `json:"name"`
-	Type               string    `json:"type"`
-	ReferenceId        string    `json:"referenceId"`
-	Link               string    `json:"link"`
-	Description        string    `json:"description"`
-	AcceptanceCriteria string    `json:"acceptanceCriteria"`
-	Votes              []*Vote   `json:"votes"`
-	Points             string    `json:"points"`
-	Active             bool      `json:"active"`
-	Skipped            bool      `json:"skipped"`
-	VoteStartTime      time.Time `json:"voteStartTime"`
-	VoteEndTime        time.Time `json:"voteEndTime"`
+	Id                 string                      `json:"id"`
+	Name               string                      `json:"name"`
+	Type               string                      `json:"type"`
+	ReferenceId        string                      `json:"referenceId"`
+	Link               string                      `json:"link"`
+	Description        string                      `json:"description"`
+	AcceptanceCriteria string                      `json:"acceptanceCriteria"`
+	Votes              []*Vote                     `json:"votes"`
+	Points             string                      `json:"points"`
+	Active             bool                        `json:"active"`
+	Skipped            bool                        `json:"skipped"`
+	VoteStartTime      time.Time                   `json:"voteStartTime"`
+	VoteEndTime        time.Time                   `json:"voteEndTime"`
+	Results            *PlanVoteResults            `json:"results"`
+	Rounds             []*PlanVoteRound            `json:"rounds"`
+	DimensionPoints    map[string]string           `json:"dimensionPoints"`
+	DimensionResults   map[string]*PlanVoteResults `json:"dimensionResults"`
+	Comments           []*PlanComment              `json:"comments"`
+	ActualPoints       string                      `json:"actualPoints"`
+	ActualEffort       *float64                    `json:"actualEffort"`
+	BreakoutId         string                      `json:"breakoutId"`
+}
+
+// BattleBreakout a group of the battles users estimating a subset of its plans in parallel, led by a sub-leader
+type BattleBreakout struct {
+	Id           string   `json:"id"`
+	Name         string   `json:"name"`
+	LeaderId     string   `json:"leaderId"`
+	ActivePlanId string   `json:"activePlanId"`
+	UserIds      []string `json:"userIds"`
+	PlanIds      []string `json:"planIds"`
+}
+
+// PlanActual the actual points and effort recorded for a finalized plan by its reference ID
+type PlanActual struct {
+	ReferenceId  string   `json:"referenceId"`
+	ActualPoints string   `json:"actualPoints"`
+	ActualEffort *float64 `json:"actualEffort"`
+}
+
+// EstimationAccuracy compares a teams estimated points with the actuals recorded against them
+type EstimationAccuracy struct {
+	ByPoints []*PointsAccuracy `json:"byPoints"`
+	ByUser   []*UserAccuracy   `json:"byUser"`
+	OverTime []*PeriodAccuracy `json:"overTime"`
+}
+
+// PointsAccuracy the actuals of the plans finalized with a point value
+type PointsAccuracy struct {
+	Points              string  `json:"points"`
+	PlanCount           int     `json:"planCount"`
+	ExactCount          int     `json:"exactCount"`
+	AverageActualPoints float64 `json:"averageActualPoints"`
+	AverageActualEffort float64 `json:"averageActualEffort"`
+}
+
+// UserAccuracy how close a users votes were to the plans actual points
+type UserAccuracy struct {
+	UserId           string  `json:"userId"`
+	UserName         string  `json:"userName"`
+	VoteCount        int     `json:"voteCount"`
+	ExactCount       int     `json:"exactCount"`
+	AverageDeviation float64 `json:"averageDeviation"`
+}
+
+// PeriodAccuracy how close the finalized points were to the actual points for plans finalized in the month
+type PeriodAccuracy struct {
+	Period           string  `json:"period"`
+	PlanCount        int     `json:"planCount"`
+	ExactCount       int     `json:"exactCount"`
+	AverageDeviation float64 `json:"averageDeviation"`
+}
+
+// PlanComment A plan comment by a user
+type PlanComment struct {
+	Id          string    `json:"id"`
+	PlanId      string    `json:"planId"`
+	UserId      string    `json:"userId"`
+	Comment     string    `json:"comment"`
+	CreatedDate time.Time `json:"createdDate"`
+	UpdatedDate time.Time `json:"updatedDate"`
+}
+
+// PlanVoteRound a completed round of voting on a plan
+type PlanVoteRound struct {
+	Round     int              `json:"round"`
+	Votes     []*Vote          `json:"votes"`
+	Results   *PlanVoteResults `json:"results"`
+	StartTime time.Time        `json:"startTime"`
+	EndTime   time.Time        `json:"endTime"`
+}
+
+// PlanVoteResults the statistics computed from a plans votes when voting ends,
+// spectator votes are excluded and the undecided vote (?) is only counted in the distribution
+type PlanVoteResults struct {
+	VoteCount    int            `json:"voteCount"`
+	Average      float64        `json:"average"`
+	Median       string         `json:"median"`
+	Mode         []string       `json:"mode"`
+	Min          string         `json:"min"`
+	Max          string         `json:"max"`
+	Distribution map[string]int `json:"distribution"`
+	Consensus    bool           `json:"consensus"`
+	// ConfidenceCount the number of votes with a confidence level, LowConfidence those at or below the low threshold
+	ConfidenceCount   int     `json:"confidenceCount"`
+	AverageConfidence float64 `json:"averageConfidence"`
+	LowConfidence     int     `json:"lowConfidence"`
 }
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// ObserverToken a revocable token granting a read-only websocket subscription to a battle, retro or storyboard
+type ObserverToken struct {
+	Id          string    `json:"id"`
+	Prefix      string    `json:"prefix"`
+	Name        string    `json:"name"`
+	EntityType  string    `json:"entityType"`
+	EntityId    string    `json:"entityId"`
+	UserId      string    `json:"userId"`
+	Token       string    `json:"token,omitempty"`
+	CreatedDate time.Time `json:"createdDate"`
+}
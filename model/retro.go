@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // Color is a color legend
 type Color struct {
 	Color  string `json:"color"`
@@ -17,19 +19,44 @@ type RetroUser struct {
 
 // Retro A story mapping board
 type Retro struct {
-	Id          string         `json:"id" db:"id"`
-	OwnerID     string         `json:"ownerId" db:"owner_id"`
-	Name        string         `json:"name" db:"name"`
-	Users       []*RetroUser   `json:"users"`
-	Groups      []*RetroGroup  `json:"groups"`
-	Items       []*RetroItem   `json:"items"`
-	ActionItems []*RetroAction `json:"actionItems"`
-	Votes       []*RetroVote   `json:"votes"`
-	Format      string         `json:"format" db:"format"`
-	Phase       string         `json:"phase" db:"phase"`
-	JoinCode    string         `json:"joinCode" db:"join_code"`
-	CreatedDate string         `json:"createdDate" db:"created_date"`
-	UpdatedDate string         `json:"updatedDate" db:"updated_date"`
+	Id                    string               `json:"id" db:"id"`
+	OwnerID               string               `json:"ownerId" db:"owner_id"`
+	Name                  string               `json:"name" db:"name"`
+	Users                 []*RetroUser         `json:"users"`
+	Groups                []*RetroGroup        `json:"groups"`
+	Items                 []*RetroItem         `json:"items"`
+	ActionItems           []*RetroAction       `json:"actionItems"`
+	ReviewActions         []*RetroReviewAction `json:"reviewActions"`
+	Votes                 []*RetroVote         `json:"votes"`
+	Format                string               `json:"format" db:"format"`
+	Columns               []*RetroColumn       `json:"columns"`
+	Phase                 string               `json:"phase" db:"phase"`
+	MaxVotes              int                  `json:"maxVotes" db:"max_votes"`
+	AllowCumulativeVoting bool                 `json:"allowCumulativeVoting" db:"allow_cumulative_voting"`
+	HideVotesDuringVoting bool                 `json:"hideVotesDuringVoting" db:"hide_votes_during_voting"`
+	JoinCode              string               `json:"joinCode" db:"join_code"`
+	CreatedDate           string               `json:"createdDate" db:"created_date"`
+	UpdatedDate           string               `json:"updatedDate" db:"updated_date"`
+}
+
+// RetroColumn is a column of the retro format that feedback items are added to, its key is the items type
+type RetroColumn struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+	Icon   string `json:"icon"`
+	Prompt string `json:"prompt"`
+}
+
+// RetroTemplate is a user or teams saved retro format with its columns
+type RetroTemplate struct {
+	Id          string         `json:"id"`
+	Name        string         `json:"name"`
+	UserId      string         `json:"userId"`
+	TeamId      string         `json:"teamId"`
+	Columns     []*RetroColumn `json:"columns"`
+	CreatedDate time.Time      `json:"createdDate"`
+	UpdatedDate time.Time      `json:"updatedDate"`
 }
 
 // RetroItem can be a pro (went well/worked), con (needs improvement), or a question
@@ -49,14 +76,49 @@ type RetroGroup struct {
 
 // RetroAction is an action the team can take based on retro feedback
 type RetroAction struct {
-	RetroID   string `json:"retroId,omitempty"`
-	ID        string `json:"id" db:"id"`
-	Content   string `json:"content" db:"content"`
-	Completed bool   `json:"completed" db:"completed"`
+	RetroID   string                `json:"retroId,omitempty"`
+	ID        string                `json:"id" db:"id"`
+	Content   string                `json:"content" db:"content"`
+	Completed bool                  `json:"completed" db:"completed"`
+	Status    string                `json:"status" db:"status"`
+	Priority  string                `json:"priority" db:"priority"`
+	DueDate   *time.Time            `json:"dueDate" db:"due_date"`
+	Assignees []*RetroUser          `json:"assignees"`
+	Comments  []*RetroActionComment `json:"comments"`
+}
+
+// RetroReviewAction is an incomplete action carried over from a previous team retro to be closed, kept or dropped
+type RetroReviewAction struct {
+	Action    *RetroAction `json:"action"`
+	RetroName string       `json:"retroName"`
+	Decision  string       `json:"decision"`
+}
+
+// RetroActionReminder is an overdue retro action to remind one of its assignees about
+type RetroActionReminder struct {
+	UserID    string    `json:"userId"`
+	UserName  string    `json:"userName"`
+	UserEmail string    `json:"userEmail"`
+	ActionID  string    `json:"actionId"`
+	Content   string    `json:"content"`
+	DueDate   time.Time `json:"dueDate"`
+	RetroID   string    `json:"retroId"`
+	RetroName string    `json:"retroName"`
+}
+
+// RetroActionComment is a users comment on a retro action
+type RetroActionComment struct {
+	ID          string    `json:"id"`
+	ActionID    string    `json:"actionId"`
+	UserID      string    `json:"userId"`
+	Comment     string    `json:"comment"`
+	CreatedDate time.Time `json:"createdDate"`
+	UpdatedDate time.Time `json:"updatedDate"`
 }
 
 // RetroVote is a users vote toward a retro item group
 type RetroVote struct {
 	UserID  string `json:"userId" db:"user_id"`
 	GroupID string `json:"groupId" db:"group_id"`
+	Count   int    `json:"count" db:"count"`
 }
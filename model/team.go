@@ -40,3 +40,76 @@ type CheckinComment struct {
 	CreateDate  string `json:"created_date"`
 	UpdatedDate string `json:"updated_date"`
 }
+
+// TeamVelocity the points a team finalized per period with an optional forecast
+type TeamVelocity struct {
+	PeriodWeeks   int               `json:"periodWeeks"`
+	Periods       []*VelocityPeriod `json:"periods"`
+	AveragePoints float64           `json:"averagePoints"`
+	Forecast      *VelocityForecast `json:"forecast,omitempty"`
+}
+
+// VelocityPeriod the points finalized in a week or sprint
+type VelocityPeriod struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Points    float64   `json:"points"`
+	PlanCount int       `json:"planCount"`
+}
+
+// VelocityForecast a Monte Carlo forecast of how long finishing the points takes
+type VelocityForecast struct {
+	Points      float64               `json:"points"`
+	Trials      int                   `json:"trials"`
+	Percentiles []*ForecastPercentile `json:"percentiles"`
+}
+
+// ForecastPercentile the number of periods (and weeks) the points are finished within at the confidence level,
+// when beyond the forecast horizon they take more than the periods
+type ForecastPercentile struct {
+	Confidence    int  `json:"confidence"`
+	Periods       int  `json:"periods"`
+	Weeks         int  `json:"weeks"`
+	BeyondHorizon bool `json:"beyondHorizon"`
+}
+
+// Sprint a team iteration with the plans committed to it from any of the teams battles
+type Sprint struct {
+	Id              string        `json:"id"`
+	TeamId          string        `json:"teamId"`
+	Name            string        `json:"name"`
+	StartDate       time.Time     `json:"startDate"`
+	EndDate         time.Time     `json:"endDate"`
+	Capacity        float64       `json:"capacity"`
+	CommittedPoints float64       `json:"committedPoints"`
+	DonePoints      float64       `json:"donePoints"`
+	Plans           []*SprintPlan `json:"plans"`
+	CreatedDate     time.Time     `json:"createdDate"`
+	UpdatedDate     time.Time     `json:"updatedDate"`
+}
+
+// SprintPlan a plan committed to a sprint
+type SprintPlan struct {
+	PlanId        string     `json:"planId"`
+	BattleId      string     `json:"battleId"`
+	Name          string     `json:"name"`
+	ReferenceId   string     `json:"referenceId"`
+	Points        string     `json:"points"`
+	Done          bool       `json:"done"`
+	DoneDate      *time.Time `json:"doneDate"`
+	CommittedDate time.Time  `json:"committedDate"`
+}
+
+// SprintBurnup the sprints committed scope and completed points per day
+type SprintBurnup struct {
+	SprintId string             `json:"sprintId"`
+	Capacity float64            `json:"capacity"`
+	Days     []*SprintBurnupDay `json:"days"`
+}
+
+// SprintBurnupDay the committed scope and completed points at the end of a sprint day
+type SprintBurnupDay struct {
+	Date      time.Time `json:"date"`
+	Scope     float64   `json:"scope"`
+	Completed float64   `json:"completed"`
+}